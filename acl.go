@@ -0,0 +1,81 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// access control list of a splunk object
+type ACL struct {
+	Owner   string `json:"owner"`
+	App     string `json:"app"`
+	Sharing string `json:"sharing"` // user, app or global
+
+	Perms struct {
+		Read  []string `json:"read"`  // users/roles allowed to read, "*" for everyone
+		Write []string `json:"write"` // users/roles allowed to write, "*" for everyone
+	} `json:"perms"`
+}
+
+// Get the ACL of a search job
+func (c Connection) SearchJobACL(jobID string) (ACL, error) {
+	return c.SearchJobACLContext(context.Background(), jobID)
+}
+
+func (c Connection) SearchJobACLContext(ctx context.Context, jobID string) (ACL, error) {
+	data := make(url.Values)
+	data.Add("output_mode", "json")
+
+	resp, respCode, err := c.httpCall(ctx, "GET", c.servicePath(fmt.Sprintf("/search/jobs/%s/acl?%s", jobID, data.Encode())), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return ACL{}, fmt.Errorf("unable to get search job acl %w %d %s", err, respCode, string(resp))
+	}
+
+	respStruct := struct {
+		Entry []struct {
+			ACL ACL `json:"acl"`
+		} `json:"entry"`
+	}{}
+	if err = json.Unmarshal(resp, &respStruct); err != nil {
+		return ACL{}, fmt.Errorf("unable to parse acl from splunk: %s | response: %s", err, string(resp))
+	}
+	if len(respStruct.Entry) == 0 {
+		return ACL{}, fmt.Errorf("no acl returned by splunk for search job %s", jobID)
+	}
+
+	return respStruct.Entry[0].ACL, nil
+}
+
+// Update the ACL of a search job, e.g. to share its results with other users or roles.
+// App is ignored, empty Owner and Sharing are left unchanged
+func (c Connection) SearchJobSetACL(jobID string, acl ACL) error {
+	return c.SearchJobSetACLContext(context.Background(), jobID, acl)
+}
+
+func (c Connection) SearchJobSetACLContext(ctx context.Context, jobID string, acl ACL) error {
+	data := make(url.Values)
+	data.Add("output_mode", "json")
+	data.Add("perms.read", strings.Join(acl.Perms.Read, ","))
+	data.Add("perms.write", strings.Join(acl.Perms.Write, ","))
+	if acl.Owner != "" {
+		data.Add("owner", acl.Owner)
+	}
+	if acl.Sharing != "" {
+		data.Add("sharing", acl.Sharing)
+	}
+
+	headers := map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	}
+
+	resp, respCode, err := c.httpCall(ctx, "POST", c.servicePath(fmt.Sprintf("/search/jobs/%s/acl", jobID)), headers, []byte(data.Encode()))
+	if err != nil || respCode != http.StatusOK {
+		return fmt.Errorf("unable to set search job acl %w %d %s", err, respCode, string(resp))
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package go_splunk_rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// atom feed returned by splunk endpoints in their default (xml) output mode
+type AtomFeed struct {
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Entries []AtomEntry `xml:"entry"`
+}
+
+type AtomEntry struct {
+	Title   string `xml:"title"`
+	ID      string `xml:"id"`
+	Updated string `xml:"updated"`
+	Author  string `xml:"author>name"`
+	Links   []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+
+	// the entry's <s:dict> content, nested dicts decode to map[string]interface{},
+	// lists to []interface{} and values to string
+	Content AtomContent `xml:"content"`
+}
+
+type AtomContent map[string]interface{}
+
+func (a *AtomContent) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	v, err := decodeAtomValue(d)
+	if err != nil {
+		return err
+	}
+
+	content, ok := v.(map[string]interface{})
+	if !ok {
+		// content is not a dict, keep it under an empty key
+		content = map[string]interface{}{"": v}
+	}
+	*a = content
+
+	return nil
+}
+
+// decode the value of the current element, up to and including its end element
+func decodeAtomValue(d *xml.Decoder) (interface{}, error) {
+	var text strings.Builder
+	var value interface{}
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "dict":
+				value, err = decodeAtomDict(d)
+			case "list":
+				value, err = decodeAtomList(d)
+			default:
+				value, err = decodeAtomValue(d)
+			}
+			if err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			if value != nil {
+				return value, nil
+			}
+			return strings.TrimSpace(text.String()), nil
+		}
+	}
+}
+
+// decode a <s:dict> of <s:key name="..."> elements
+func decodeAtomDict(d *xml.Decoder) (map[string]interface{}, error) {
+	dict := make(map[string]interface{})
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := ""
+			for _, attr := range t.Attr {
+				if attr.Name.Local == "name" {
+					name = attr.Value
+				}
+			}
+
+			v, err := decodeAtomValue(d)
+			if err != nil {
+				return nil, err
+			}
+			dict[name] = v
+		case xml.EndElement:
+			return dict, nil
+		}
+	}
+}
+
+// decode a <s:list> of <s:item> elements
+func decodeAtomList(d *xml.Decoder) ([]interface{}, error) {
+	list := []interface{}{}
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok.(type) {
+		case xml.StartElement:
+			v, err := decodeAtomValue(d)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		case xml.EndElement:
+			return list, nil
+		}
+	}
+}
+
+// Decode an atom feed (or a single atom entry) as returned by splunk
+func DecodeAtomFeed(r io.Reader) (AtomFeed, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return AtomFeed{}, err
+	}
+
+	var feed AtomFeed
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return AtomFeed{}, fmt.Errorf("unable to parse atom feed from splunk: %s", err)
+	}
+
+	// some endpoints return a single <entry> instead of a <feed>
+	if len(feed.Entries) == 0 && bytes.Contains(body, []byte("<entry")) {
+		var entry AtomEntry
+		if err := xml.Unmarshal(body, &entry); err == nil && entry.ID != "" {
+			feed.Entries = []AtomEntry{entry}
+		}
+	}
+
+	return feed, nil
+}
+
+// GET an endpoint (e.g. "/search/jobs", "/saved/searches") in the Connection's namespace
+// in its default xml output mode, and decode the atom feed.
+// for endpoints that don't fully support output_mode=json
+func (c Connection) GetAtomFeed(endpoint string) (AtomFeed, error) {
+	return c.GetAtomFeedContext(context.Background(), endpoint)
+}
+
+func (c Connection) GetAtomFeedContext(ctx context.Context, endpoint string) (AtomFeed, error) {
+	resp, respCode, err := c.httpCall(ctx, "GET", c.servicePath(endpoint), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return AtomFeed{}, fmt.Errorf("unable to get %s %w %d %s", endpoint, err, respCode, string(resp))
+	}
+
+	return DecodeAtomFeed(bytes.NewReader(resp))
+}
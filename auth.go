@@ -1,12 +1,12 @@
 package go_splunk_rest
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
-	"time"
 )
 
 type AuthenticationType string
@@ -15,11 +15,16 @@ const BasicAuth AuthenticationType = "basic"
 const AuthenticationTokenAuth AuthenticationType = "authentication-token"
 const AuthorizationTokenAuth AuthenticationType = "authorization-token"
 
+// authenticate with the client certificate in ClientCertFile/ClientKeyFile only (mutual TLS),
+// no Authorization header is sent
+const ClientCertAuth AuthenticationType = "client-cert"
+
 func ParseAuthenticationType(s string) (c AuthenticationType, err error) {
 	authenticationTypes := map[AuthenticationType]bool{
 		BasicAuth:               true,
 		AuthenticationTokenAuth: true,
 		AuthorizationTokenAuth:  true,
+		ClientCertAuth:          true,
 	}
 
 	authenticationType := AuthenticationType(s)
@@ -35,47 +40,66 @@ func GetAllAuthenticationTypes() []AuthenticationType {
 		BasicAuth,
 		AuthenticationTokenAuth,
 		AuthorizationTokenAuth,
+		ClientCertAuth,
 	}
 }
 
-func (c Connection) getSessionKey() error {
+// log in with the Connection's username and password, returning a new session key
+func (c Connection) getSessionKey(ctx context.Context) (string, error) {
 	data := make(url.Values)
 	data.Add("username", c.Username)
 	data.Add("password", c.Password)
 	data.Add("output_mode", "json")
 
-	resp, respCode, err := c.httpCall("POST", "/services/auth/login", map[string]string{}, []byte(data.Encode()))
+	// the login request itself is not authenticated
+	anon := c
+	anon.AuthType = ""
+	anon.AuthFallback = nil
+	anon.CredentialProvider = nil
+
+	resp, respCode, err := anon.httpCall(ctx, "POST", "/services/auth/login", map[string]string{}, []byte(data.Encode()))
 	if err != nil || respCode != http.StatusOK {
-		return fmt.Errorf("unable to get sessionKey %s", err)
+		return "", fmt.Errorf("unable to get sessionKey %w %d", err, respCode)
 	}
 
 	respStruct := struct {
 		SessionKey string `json:"sessionKey"`
 	}{}
 	if err = json.Unmarshal(resp, &respStruct); err != nil {
-		return fmt.Errorf("unable to parse sessionKey from splunk: %s | response: %s", err, string(resp))
+		return "", fmt.Errorf("unable to parse sessionKey from splunk: %s | response: %s", err, string(resp))
 	}
 
-	c.sessionKey = respStruct.SessionKey
-	c.sessionKeyLastUsed = time.Now()
-
-	return nil
+	return respStruct.SessionKey, nil
 }
 
 func (c Connection) wrapAuth(req *http.Request) error {
+	c, err := c.withCredentials(req.Context())
+	if err != nil {
+		return err
+	}
+
 	if c.AuthType == BasicAuth {
 		req.Header.Set("Authorization", "Basic "+
 			base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", c.Username, c.Password))))
 	} else if c.AuthType == AuthenticationTokenAuth {
-		req.Header.Set("Authorization", "Bearer "+c.AuthenticationToken)
-	} else if c.AuthType == AuthorizationTokenAuth {
-		if c.sessionKey == "" || c.sessionKeyLastUsed.Add(time.Hour).Before(time.Now()) {
-			err := c.getSessionKey()
+		token := c.AuthenticationToken
+		if c.TokenSource != nil {
+			token, err = c.TokenSource.Token(req.Context())
 			if err != nil {
 				return err
 			}
 		}
-		req.Header.Set("Authorization", "Splunk "+c.sessionKey)
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if c.AuthType == AuthorizationTokenAuth {
+		sessionKey, err := c.session().sessionKey(req.Context(), c.sessionPolicy(), c.loginSession)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Splunk "+sessionKey)
+	} else if c.AuthType == ClientCertAuth {
+		if c.ClientCertFile == "" {
+			return fmt.Errorf("client-cert authentication requires a client certificate")
+		}
 	}
 
 	return nil
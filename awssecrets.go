@@ -0,0 +1,161 @@
+package go_splunk_rest
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// credentials read from an AWS Secrets Manager secret, whose SecretString is
+// a JSON object with the username, password and token keys.
+// requests are signed with the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN environment variables unless the keys are set
+type AWSSecretsManagerCredentials struct {
+	Region   string // defaults to AWS_REGION
+	SecretID string // name or ARN of the secret
+
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// keys of the secret, defaulting to username, password and token
+	UsernameKey string
+	PasswordKey string
+	TokenKey    string
+
+	Client *http.Client // defaults to a client with a HTTP_TIMEOUT timeout
+}
+
+func (a AWSSecretsManagerCredentials) GetCredentials(ctx context.Context) (Credentials, error) {
+	region := a.Region
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	accessKeyID, secretAccessKey, sessionToken := a.AccessKeyID, a.SecretAccessKey, a.SessionToken
+	if accessKeyID == "" {
+		accessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		secretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		sessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if region == "" || accessKeyID == "" || secretAccessKey == "" {
+		return Credentials{}, fmt.Errorf("aws region and credentials required to read secret %s", a.SecretID)
+	}
+	client := a.Client
+	if client == nil {
+		client = &http.Client{Timeout: HTTP_TIMEOUT * time.Second}
+	}
+
+	body, err := json.Marshal(map[string]string{"SecretId": a.SecretID})
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	url := fmt.Sprintf("https://secretsmanager.%s.amazonaws.com/", region)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+	signAWSRequest(req, body, region, "secretsmanager", accessKeyID, secretAccessKey, time.Now().UTC())
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("unable to read aws secret %s: %s", a.SecretID, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Credentials{}, fmt.Errorf("unable to read aws secret %s %d %s", a.SecretID, resp.StatusCode, string(respBody))
+	}
+
+	respStruct := struct {
+		SecretString string `json:"SecretString"`
+	}{}
+	if err = json.Unmarshal(respBody, &respStruct); err != nil {
+		return Credentials{}, fmt.Errorf("unable to parse aws secret %s: %s", a.SecretID, err)
+	}
+
+	var secret map[string]interface{}
+	if err = json.Unmarshal([]byte(respStruct.SecretString), &secret); err != nil {
+		return Credentials{}, fmt.Errorf("aws secret %s is not a JSON object: %s", a.SecretID, err)
+	}
+
+	return secretCredentials(secret, a.UsernameKey, a.PasswordKey, a.TokenKey), nil
+}
+
+// sign req with AWS Signature Version 4
+func signAWSRequest(req *http.Request, body []byte, region, service, accessKeyID, secretAccessKey string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for h, v := range req.Header {
+		headers[strings.ToLower(h)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for h := range headers {
+		names = append(names, h)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, h := range names {
+		canonicalHeaders.WriteString(h + ":" + headers[h] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	bodyHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, region, service)
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	hmacSHA256 := func(key []byte, data string) []byte {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(data))
+		return h.Sum(nil)
+	}
+	signingKey := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, hex.EncodeToString(hmacSHA256(signingKey, stringToSign))))
+}
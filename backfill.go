@@ -0,0 +1,145 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	log "log/slog"
+)
+
+type BackfillOptions struct {
+	// windows searched at once, defaults to 1 (sequentially, in time order)
+	Concurrency int
+
+	// applied to every window's search, the time bounds are overridden
+	SearchOptions SearchOptions
+
+	// called as each window completes (or fails)
+	OnWindow func(BackfillProgress)
+}
+
+// progress of a Backfill, reported for each window
+type BackfillProgress struct {
+	Window       int // index of the window, in time order
+	Windows      int // total number of windows
+	Completed    int // windows completed so far, including this one
+	EarliestTime time.Time
+	LatestTime   time.Time
+	Results      int // results written to the sink for the window
+	Err          error
+}
+
+// Run searchQuery over from - to in consecutive windows of the given size, through
+// the streaming export endpoint, writing the results to sink as they are received
+// and flushing it at the end. with a Concurrency above 1, results of concurrently
+// searched windows are interleaved in sink (writes are serialized).
+// the first window to fail cancels the others, and its error is returned
+func (c Connection) Backfill(searchQuery string, from, to time.Time, window time.Duration, sink ResultSink, backfillOptions BackfillOptions) error {
+	return c.BackfillContext(context.Background(), searchQuery, from, to, window, sink, backfillOptions)
+}
+
+func (c Connection) BackfillContext(ctx context.Context, searchQuery string, from, to time.Time, window time.Duration, sink ResultSink, backfillOptions BackfillOptions) error {
+	if window <= 0 {
+		return fmt.Errorf("invalid backfill window %s", window)
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("invalid backfill range %s - %s", from.Format(TIME_FORMAT), to.Format(TIME_FORMAT))
+	}
+
+	var windows []partitionWindow
+	for start := from; start.Before(to); start = start.Add(window) {
+		end := start.Add(window)
+		if end.After(to) {
+			end = to
+		}
+		windows = append(windows, partitionWindow{EarliestTime: start, LatestTime: end})
+	}
+
+	concurrency := backfillOptions.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var sinkMu sync.Mutex // serializes sink writes and progress reports
+	completed := 0
+	errs := make([]error, len(windows))
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, w := range windows {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(idx int, w partitionWindow) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			searchOptions := backfillOptions.SearchOptions
+			searchOptions.UseEarliestTime = true
+			searchOptions.EarliestTime = w.EarliestTime
+			searchOptions.UseLatestTime = true
+			searchOptions.LatestTime = w.LatestTime
+
+			log.Debug("backfill window",
+				"i", idx,
+				"start", w.EarliestTime.Format(TIME_FORMAT),
+				"end", w.LatestTime.Format(TIME_FORMAT))
+
+			results := 0
+			err := c.SearchExportContext(ctx, searchQuery, searchOptions, func(res map[string]interface{}) error {
+				sinkMu.Lock()
+				defer sinkMu.Unlock()
+
+				results++
+				return sink.WriteResult(res)
+			})
+			if err != nil {
+				errs[idx] = fmt.Errorf("backfill window %s - %s failed: %w",
+					w.EarliestTime.Format(TIME_FORMAT), w.LatestTime.Format(TIME_FORMAT), err)
+				cancel()
+			}
+
+			sinkMu.Lock()
+			defer sinkMu.Unlock()
+			completed++
+			if backfillOptions.OnWindow != nil {
+				backfillOptions.OnWindow(BackfillProgress{
+					Window:       idx,
+					Windows:      len(windows),
+					Completed:    completed,
+					EarliestTime: w.EarliestTime,
+					LatestTime:   w.LatestTime,
+					Results:      results,
+					Err:          errs[idx],
+				})
+			}
+		}(i, w)
+	}
+	wg.Wait()
+
+	// windows cancelled because of another window's failure report context.Canceled,
+	// return the failure that caused it
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return err
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return sink.Flush()
+}
@@ -0,0 +1,139 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const BATCH_CONCURRENCY = 4
+
+// a search of a SearchBatch, identified by ID in its results
+type QuerySpec struct {
+	ID            string
+	Query         string
+	SearchOptions SearchOptions
+}
+
+type BatchOptions struct {
+	// max searches of the batch running at once, defaults to BATCH_CONCURRENCY.
+	// Connection.MaxConcurrentSearches still applies across batches
+	Concurrency int
+	// cancel the searches left as soon as one fails
+	FailFast bool
+}
+
+// returned by SearchBatch, along with the results of the searches which succeeded,
+// when some searches failed. the partial results of a search failing with a
+// *PartialResultsError (see SearchOptions.AllowPartialResults) are returned too
+type BatchError struct {
+	Errors map[string]error // keyed by QuerySpec ID
+}
+
+func (e *BatchError) Error() string {
+	ids := make([]string, 0, len(e.Errors))
+	for id := range e.Errors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	msgs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", id, e.Errors[id]))
+	}
+
+	return fmt.Sprintf("%d searches failed: %s", len(ids), strings.Join(msgs, "; "))
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+func (c Connection) SearchBatch(queries []QuerySpec, batchOptions BatchOptions) (map[string]SearchResult, error) {
+	return c.SearchBatchContext(context.Background(), queries, batchOptions)
+}
+
+// run independent searches concurrently (each as the Search function would),
+// and wait for all of them. results are keyed by QuerySpec ID
+func (c Connection) SearchBatchContext(ctx context.Context, queries []QuerySpec, batchOptions BatchOptions) (map[string]SearchResult, error) {
+	for i, q := range queries {
+		for _, prev := range queries[:i] {
+			if prev.ID == q.ID {
+				return nil, fmt.Errorf("duplicate query id %q in batch", q.ID)
+			}
+		}
+	}
+
+	concurrency := batchOptions.Concurrency
+	if concurrency <= 0 {
+		concurrency = BATCH_CONCURRENCY
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// each goroutine only writes its own index
+	results := make([]SearchResult, len(queries))
+	errs := make([]error, len(queries))
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, q := range queries {
+		wg.Add(1)
+		go func(idx int, q QuerySpec) {
+			defer wg.Done()
+			defer func() {
+				// a panic in one query (e.g. in its callbacks) should fail the query,
+				// not take down the whole process
+				if r := recover(); r != nil {
+					errs[idx] = fmt.Errorf("query %s panicked: %v\n%s", q.ID, r, debug.Stack())
+					if batchOptions.FailFast {
+						cancel()
+					}
+				}
+			}()
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[idx] = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			results[idx], errs[idx] = c.SearchDetailedContext(ctx, q.Query, q.SearchOptions)
+			if errs[idx] != nil && batchOptions.FailFast {
+				cancel()
+			}
+		}(i, q)
+	}
+	wg.Wait()
+
+	batchResults := make(map[string]SearchResult, len(queries))
+	batchErr := &BatchError{Errors: make(map[string]error)}
+	for i, q := range queries {
+		if errs[i] != nil {
+			batchErr.Errors[q.ID] = errs[i]
+			var partialErr *PartialResultsError
+			if !errors.As(errs[i], &partialErr) {
+				continue
+			}
+		}
+		batchResults[q.ID] = results[i]
+	}
+
+	if len(batchErr.Errors) > 0 {
+		return batchResults, batchErr
+	}
+
+	return batchResults, nil
+}
@@ -0,0 +1,41 @@
+package go_splunk_rest
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSearchBatchPartialResults(t *testing.T) {
+	c := newTestConnection(t, searchHandler(t, "test", 2))
+
+	latest := time.Now().Truncate(time.Second)
+	results, err := c.SearchBatch([]QuerySpec{{
+		ID:    "partial",
+		Query: "search index=main",
+		SearchOptions: SearchOptions{
+			MaxCount:            2,
+			AllowPartition:      true,
+			AllowPartialResults: true,
+			PartitionCount:      2,
+			UseEarliestTime:     true,
+			EarliestTime:        latest.Add(-time.Hour),
+			UseLatestTime:       true,
+			LatestTime:          latest,
+			JobID:               "test",
+			OnProgress: func(s SearchJobStatus) {
+				if s.Content().Sid == "test_p1" {
+					panic("boom")
+				}
+			},
+		},
+	}}, BatchOptions{})
+
+	var partialErr *PartialResultsError
+	if !errors.As(err, &partialErr) {
+		t.Fatalf("got %v, want a *PartialResultsError", err)
+	}
+	if got := len(results["partial"].Results); got != 1 {
+		t.Fatalf("got %d results, want the succeeding partition's one", got)
+	}
+}
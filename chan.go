@@ -0,0 +1,63 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"sync"
+)
+
+// Run a search, delivering its results on the returned channel as they are fetched,
+// so consumers can process results while the rest of the search is still running.
+// with AllowPartition, the results of each partition are delivered as soon as it completes
+// (so not in partition order), without waiting on the other partitions.
+// MaxResults and the paging continuity checks apply as they do to Search.
+// the results channel is closed once the search is over, after which the error channel
+// yields the error the search failed with, if any, and is closed.
+// stopping to read from the results channel without cancelling ctx (see SearchChanContext)
+// leaks the goroutine running the search
+func (c Connection) SearchChan(searchQuery string, searchOptions SearchOptions) (<-chan Result, <-chan error) {
+	return c.SearchChanContext(context.Background(), searchQuery, searchOptions)
+}
+
+func (c Connection) SearchChanContext(ctx context.Context, searchQuery string, searchOptions SearchOptions) (<-chan Result, <-chan error) {
+	resultsChan := make(chan Result, RESULTS_PAGE_SIZE)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(errChan)
+		defer close(resultsChan)
+
+		if err := c.searchChan(ctx, searchQuery, searchOptions, resultsChan); err != nil {
+			errChan <- err
+		}
+	}()
+
+	return resultsChan, errChan
+}
+
+func (c Connection) searchChan(ctx context.Context, searchQuery string, searchOptions SearchOptions, resultsChan chan<- Result) error {
+	// partitions complete concurrently, deliver their results one partition at a time
+	var mu sync.Mutex
+	delivered := 0
+	searchOptions.onResults = func(result SearchResult) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		delivered += len(result.Results)
+		if err := c.checkMaxResults(delivered); err != nil {
+			return err
+		}
+
+		for _, res := range result.Results {
+			select {
+			case resultsChan <- Result(res):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		return nil
+	}
+
+	_, err := c.search(ctx, searchQuery, searchOptions, 0)
+	return err
+}
@@ -0,0 +1,88 @@
+package go_splunk_rest
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// plain decimal numbers, strconv also accepts hex, "inf", "nan", etc.
+var numericRegexp = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+type NumericKind string
+
+const IntKind NumericKind = "int"       // int64
+const FloatKind NumericKind = "float"   // float64
+const StringKind NumericKind = "string" // leave the value as a string
+
+// options for CoerceNumbers
+type CoerceOptions struct {
+	// kind to coerce specific fields to, instead of guessing from their values.
+	// values that can't be converted to the kind are left as they are
+	FieldKinds map[string]NumericKind
+
+	// also coerce internal fields (starting with "_", e.g. _serial, _bkt),
+	// which are left as strings by default
+	IncludeInternalFields bool
+}
+
+// Return a copy of results with numeric looking string values converted
+// to int64 (integers) or float64 (other numbers), values of multivalue fields
+// are converted individually. FieldKinds overrides the guessing for specific fields
+func CoerceNumbers(results []map[string]interface{}, coerceOptions CoerceOptions) []map[string]interface{} {
+	coerced := make([]map[string]interface{}, len(results))
+	for i, res := range results {
+		row := make(map[string]interface{}, len(res))
+		for k, v := range res {
+			kind, override := coerceOptions.FieldKinds[k]
+			if !override && !coerceOptions.IncludeInternalFields && strings.HasPrefix(k, "_") {
+				kind = StringKind
+			}
+
+			row[k] = coerceValue(v, kind)
+		}
+		coerced[i] = row
+	}
+
+	return coerced
+}
+
+// convert v to kind, guessing the kind if empty
+func coerceValue(v interface{}, kind NumericKind) interface{} {
+	switch val := v.(type) {
+	case []interface{}:
+		values := make([]interface{}, len(val))
+		for i, mv := range val {
+			values[i] = coerceValue(mv, kind)
+		}
+		return values
+	case string:
+		switch kind {
+		case StringKind:
+			return val
+		case IntKind:
+			if n, err := strconv.ParseInt(val, 10, 64); err == nil {
+				return n
+			}
+			return val
+		case FloatKind:
+			if f, err := strconv.ParseFloat(val, 64); err == nil {
+				return f
+			}
+			return val
+		default:
+			if !numericRegexp.MatchString(val) {
+				return val
+			}
+			if n, err := strconv.ParseInt(val, 10, 64); err == nil {
+				return n
+			}
+			if f, err := strconv.ParseFloat(val, 64); err == nil {
+				return f
+			}
+			return val
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,26 @@
+package go_splunk_rest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCoerceNumbersIntKind(t *testing.T) {
+	results := []map[string]interface{}{
+		{"count": "12"},
+		{"count": "12.5"},
+		{"count": "n/a"},
+	}
+
+	got := CoerceNumbers(results, CoerceOptions{
+		FieldKinds: map[string]NumericKind{"count": IntKind},
+	})
+	want := []map[string]interface{}{
+		{"count": int64(12)},
+		{"count": "12.5"},
+		{"count": "n/a"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
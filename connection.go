@@ -1,15 +1,141 @@
 package go_splunk_rest
 
-import "time"
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
 
 type Connection struct {
-	Host                string             `toml:"host"`
-	AuthType            AuthenticationType `toml:"auth-type"` // basic, authorization-token, authentication-token
-	Username            string             `toml:"username"`
-	Password            string             `toml:"password"`
-	AuthenticationToken string             `toml:"authentication-token"`
-	MaxCount            int                `toml:"max-count"`
-
-	sessionKey         string    `toml:"-"`
-	sessionKeyLastUsed time.Time `toml:"-"` // sessionKey valid for one hour, and timer resets after every use
+	Host                string               `toml:"host"`
+	AuthType            AuthenticationType   `toml:"auth-type"`     // basic, authorization-token, authentication-token, client-cert
+	AuthFallback        []AuthenticationType `toml:"auth-fallback"` // tried in order when authenticating with AuthType fails (or gets a 401)
+	Username            string               `toml:"username"`
+	Password            string               `toml:"password"`
+	AuthenticationToken string               `toml:"authentication-token"`
+	MaxCount            int                  `toml:"max-count"`
+	MethodOverride      bool                 `toml:"method-override"`     // send DELETE/PUT as POST with X-HTTP-Method-Override header
+	RetryMaxAttempts    int                  `toml:"retry-max-attempts"`  // retries for failed GET/HEAD (or unsent, or throttled) http calls, 0 disables retries
+	RetryWait           time.Duration        `toml:"retry-wait"`          // initial backoff between retries, doubled on each retry, defaults to RETRY_WAIT seconds
+	RetryMaxWait        time.Duration        `toml:"retry-max-wait"`      // max backoff between retries, defaults to RETRY_MAX_WAIT seconds
+	RetryBudget         int                  `toml:"retry-budget"`        // max retries per RETRY_BUDGET_WINDOW for the Host and Username, 0 for no limit
+	DisableCompression  bool                 `toml:"disable-compression"` // don't ask splunk for gzip compressed responses
+	MaxResponseSize     int64                `toml:"max-response-size"`   // max bytes read from a (non streaming) response, 0 for no limit
+	MaxResults          int                  `toml:"max-results"`         // max results fetched by a Search (including partitions), 0 for no limit
+
+	// max search jobs dispatched and running at once by Search and SearchChan (partitions included),
+	// shared by all Connections with the same Host and Username. set it to (at most) the user's
+	// search quota to queue searches instead of getting "quota reached" failures. 0 for no limit
+	MaxConcurrentSearches int `toml:"max-concurrent-searches"`
+
+	// PEM files of the client certificate (and its key) presented to splunkd,
+	// required for ClientCertAuth, and of the CA bundle splunkd's certificate is verified
+	// against (instead of the system roots). the files are reloaded when they change
+	ClientCertFile string `toml:"client-cert-file"`
+	ClientKeyFile  string `toml:"client-key-file"`
+	CACertFile     string `toml:"ca-cert-file"`
+
+	MinTLSVersion string `toml:"min-tls-version"` // "1.0", "1.1", "1.2" or "1.3", defaults to Go's minimum
+	TLSServerName string `toml:"tls-server-name"` // name splunkd's certificate is verified against, defaults to Host's
+
+	// don't verify splunkd's certificate at all, e.g. for the default self-signed one.
+	// Not Recommended, prefer adding the certificate to CACertFile
+	InsecureSkipVerify bool `toml:"insecure-skip-verify"`
+
+	// namespace to dispatch searches and access knowledge objects in,
+	// requests go to /servicesNS/{owner}/{app}/... when either is set ("-" is used for the unset one)
+	Owner string `toml:"owner"`
+	App   string `toml:"app"`
+
+	// applied in order to every result row fetched through the Connection
+	// (field renaming, PII masking, type conversion, ...), before it is returned.
+	// fetching raw results they can't be applied to (SearchExportCSV) fails with ErrRawResults
+	ResultTransforms []ResultTransform `toml:"-"`
+
+	// decides if a failed http call should be retried, overriding the default status-code logic
+	// resp is nil when err is set
+	RetryClassifier func(resp *http.Response, err error) bool `toml:"-"`
+
+	// called each time splunkd throttles a request (429, or 503 with Retry-After),
+	// e.g. to count or alert on rate limiting
+	OnThrottle func(Throttle) `toml:"-"`
+
+	// with AuthenticationTokenAuth, supplies the token instead of AuthenticationToken,
+	// e.g. a RefreshingTokenSource renewing it before it expires
+	TokenSource TokenSource `toml:"-"`
+
+	// supplies the credentials instead of Username, Password and AuthenticationToken,
+	// e.g. EnvCredentials, VaultCredentials or AWSSecretsManagerCredentials
+	CredentialProvider CredentialProvider `toml:"-"`
+
+	// with AuthorizationTokenAuth, persists session keys so other processes
+	// (or the next run) reuse them rather than logging in again, e.g. FileSessionStore
+	SessionStore SessionStore `toml:"-"`
+
+	// with AuthorizationTokenAuth, how long a session key is used, defaults to SESSION_KEY_TTL.
+	// set it below the server's session timeout (server.conf sessionTimeout)
+	SessionTTL     time.Duration  `toml:"session-ttl"`
+	SessionRefresh SessionRefresh `toml:"session-refresh"` // sliding (default) or fixed
+
+	// HTTP Event Collector token, used (only) by HECSend. it is not interchangeable
+	// with the REST authentication token. HECHost is the collector's scheme://host:port
+	// (usually port 8088), defaults to Host
+	HECToken string `toml:"hec-token"`
+	HECHost  string `toml:"hec-host"`
+
+	// proxy requests go through, http://, https:// or socks5://host:port,
+	// defaults to the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+	ProxyURL string `toml:"proxy-url"`
+
+	// authentication to the proxy requests go through, independent of the splunk authentication:
+	// basic with ProxyUsername/ProxyPassword, and/or custom ProxyHeaders (e.g. a proxy token).
+	// a SOCKS5 proxy only supports ProxyUsername/ProxyPassword
+	ProxyUsername string            `toml:"proxy-username"`
+	ProxyPassword string            `toml:"proxy-password"`
+	ProxyHeaders  map[string]string `toml:"proxy-headers"`
+
+	// keep the cookies set by splunkd (or the load balancer in front of a search head cluster),
+	// so requests stick to the search head a job was dispatched on.
+	// StickySessions uses a jar shared by the Connections with the same Host and Username,
+	// CookieJar overrides it
+	StickySessions bool           `toml:"sticky-sessions"`
+	CookieJar      http.CookieJar `toml:"-"`
+
+	// sends the requests instead of the transport built from the Connection's TLS, proxy and
+	// compression settings (which are then ignored), e.g. to instrument requests.
+	// HTTPClient takes precedence over Transport, its Jar is used when set.
+	// request timeouts still apply, through the request's context
+	HTTPClient *http.Client      `toml:"-"`
+	Transport  http.RoundTripper `toml:"-"`
+
+	// session key cache set by WithSession, nil to share one by Host and credentials
+	sessions *sessionManager
+}
+
+// REST path of endpoint (e.g. "/search/jobs") in the Connection's namespace
+func (c Connection) servicePath(endpoint string) string {
+	if c.Owner == "" && c.App == "" {
+		return "/services" + endpoint
+	}
+
+	owner, app := "-", "-"
+	if c.Owner != "" {
+		owner = url.PathEscape(c.Owner)
+	}
+	if c.App != "" {
+		app = url.PathEscape(c.App)
+	}
+
+	return fmt.Sprintf("/servicesNS/%s/%s%s", owner, app, endpoint)
+}
+
+// copy of the Connection with requests in username's namespace (/servicesNS/{username}/...),
+// so search jobs are dispatched owned by username rather than the Connection's user.
+// saved searches with dispatchAs "user" (see SavedSearchSetDispatchAs) dispatched through it
+// run with username's roles, restricting them to the data username can access.
+// the Connection's user needs the capabilities to act in other users' namespaces (e.g. admin)
+func (c Connection) AsUser(username string) Connection {
+	c.Owner = username
+	return c
 }
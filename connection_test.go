@@ -0,0 +1,21 @@
+package go_splunk_rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Connection to a fake splunkd serving handler, authenticated with a token
+func newTestConnection(t *testing.T, handler http.HandlerFunc) Connection {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return Connection{
+		Host:                srv.URL,
+		AuthType:            AuthenticationTokenAuth,
+		AuthenticationToken: "test-token",
+	}
+}
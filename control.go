@@ -0,0 +1,88 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// POST a control action to a search job, a low level escape hatch
+// for control actions that don't have their own method (e.g. "save", "unsave", "enablepreview")
+// more details can be found here:
+// https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTsearch#search.2Fjobs.2F.7Bsearch_id.7D.2Fcontrol
+func (c Connection) SearchJobControl(jobID, action string, params url.Values) error {
+	return c.SearchJobControlContext(context.Background(), jobID, action, params)
+}
+
+func (c Connection) SearchJobControlContext(ctx context.Context, jobID, action string, params url.Values) error {
+	data := make(url.Values)
+	for k, v := range params {
+		data[k] = v
+	}
+	data.Set("action", action)
+	data.Set("output_mode", "json")
+
+	headers := map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	}
+
+	resp, respCode, err := c.httpCall(ctx, "POST", c.servicePath(fmt.Sprintf("/search/jobs/%s/control", jobID)), headers, []byte(data.Encode()))
+	if err != nil || respCode != http.StatusOK {
+		return fmt.Errorf("unable to %s search job %w %d %s", action, err, respCode, string(resp))
+	}
+	c.wakeJobWaiters(jobID)
+
+	return nil
+}
+
+// Stop a running search job, the job's results are discarded
+func (c Connection) SearchJobCancel(jobID string) error {
+	return c.SearchJobControl(jobID, "cancel", nil)
+}
+
+// Stop a running search job from scanning further events,
+// results gathered so far are kept and can be retrieved
+func (c Connection) SearchJobFinalize(jobID string) error {
+	return c.SearchJobControl(jobID, "finalize", nil)
+}
+
+// Suspend a running search job without discarding it
+func (c Connection) SearchJobPause(jobID string) error {
+	return c.SearchJobControl(jobID, "pause", nil)
+}
+
+// Resume a search job suspended with SearchJobPause
+func (c Connection) SearchJobUnpause(jobID string) error {
+	return c.SearchJobControl(jobID, "unpause", nil)
+}
+
+// Extend the expiration of a search job's artifacts by its current ttl
+func (c Connection) SearchJobTouch(jobID string) error {
+	return c.SearchJobControl(jobID, "touch", nil)
+}
+
+// Change the ttl of a search job's artifacts, the ttl is rounded down to whole seconds
+func (c Connection) SearchJobSetTTL(jobID string, ttl time.Duration) error {
+	params := make(url.Values)
+	params.Add("ttl", fmt.Sprintf("%d", int(ttl.Seconds())))
+
+	return c.SearchJobControl(jobID, "setttl", params)
+}
+
+const MIN_JOB_PRIORITY = 0
+const MAX_JOB_PRIORITY = 10
+
+// Change the priority of a search job, priority ranges from
+// MIN_JOB_PRIORITY (lowest) to MAX_JOB_PRIORITY (highest)
+func (c Connection) SearchJobSetPriority(jobID string, priority int) error {
+	if priority < MIN_JOB_PRIORITY || priority > MAX_JOB_PRIORITY {
+		return fmt.Errorf("invalid priority: %d, must be between %d and %d", priority, MIN_JOB_PRIORITY, MAX_JOB_PRIORITY)
+	}
+
+	params := make(url.Values)
+	params.Add("priority", fmt.Sprintf("%d", priority))
+
+	return c.SearchJobControl(jobID, "setpriority", params)
+}
@@ -0,0 +1,33 @@
+package go_splunk_rest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
+)
+
+// cookie jars of StickySessions Connections, keyed by host and username.
+// shared by copies of a Connection, as Connection is passed by value
+var cookieJars sync.Map
+
+// cookie jar the Connection's requests use, nil for none
+func (c Connection) cookieJar() http.CookieJar {
+	if c.CookieJar != nil {
+		return c.CookieJar
+	}
+	if !c.StickySessions {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s|%s", c.Host, c.Username)
+	if jar, ok := cookieJars.Load(key); ok {
+		return jar.(http.CookieJar)
+	}
+
+	// cookiejar.New never fails without options
+	jar, _ := cookiejar.New(nil)
+	actual, _ := cookieJars.LoadOrStore(key, jar)
+
+	return actual.(http.CookieJar)
+}
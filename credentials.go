@@ -0,0 +1,134 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// credentials used to authenticate a Connection,
+// Username and Password for BasicAuth and AuthorizationTokenAuth, Token for AuthenticationTokenAuth
+type Credentials struct {
+	Username string
+	Password string
+	Token    string
+}
+
+// supplies the Connection's credentials, instead of the Username, Password and AuthenticationToken fields.
+// called for every request, see NewCachingCredentialProvider for providers backed by a remote secret store
+type CredentialProvider interface {
+	GetCredentials(ctx context.Context) (Credentials, error)
+}
+
+// fixed credentials, e.g. read from a config file
+type StaticCredentials Credentials
+
+func (s StaticCredentials) GetCredentials(ctx context.Context) (Credentials, error) {
+	return Credentials(s), nil
+}
+
+const ENV_SPLUNK_USERNAME = "SPLUNK_USERNAME"
+const ENV_SPLUNK_PASSWORD = "SPLUNK_PASSWORD"
+const ENV_SPLUNK_TOKEN = "SPLUNK_TOKEN"
+
+// credentials read from environment variables, ENV_SPLUNK_USERNAME, ENV_SPLUNK_PASSWORD
+// and ENV_SPLUNK_TOKEN unless set. fails if none of the variables are set
+type EnvCredentials struct {
+	UsernameVar string
+	PasswordVar string
+	TokenVar    string
+}
+
+func (e EnvCredentials) GetCredentials(ctx context.Context) (Credentials, error) {
+	orDefault := func(v, def string) string {
+		if v == "" {
+			return def
+		}
+		return v
+	}
+
+	usernameVar := orDefault(e.UsernameVar, ENV_SPLUNK_USERNAME)
+	passwordVar := orDefault(e.PasswordVar, ENV_SPLUNK_PASSWORD)
+	tokenVar := orDefault(e.TokenVar, ENV_SPLUNK_TOKEN)
+
+	creds := Credentials{
+		Username: os.Getenv(usernameVar),
+		Password: os.Getenv(passwordVar),
+		Token:    os.Getenv(tokenVar),
+	}
+	if creds == (Credentials{}) {
+		return creds, fmt.Errorf("none of %s, %s, %s are set", usernameVar, passwordVar, tokenVar)
+	}
+
+	return creds, nil
+}
+
+// caches the credentials of a provider for ttl, so they are not fetched from it
+// for every request. concurrent callers wait on a single fetch
+func NewCachingCredentialProvider(provider CredentialProvider, ttl time.Duration) CredentialProvider {
+	return &cachingCredentialProvider{provider: provider, ttl: ttl}
+}
+
+type cachingCredentialProvider struct {
+	provider CredentialProvider
+	ttl      time.Duration
+
+	mu        sync.Mutex
+	creds     Credentials
+	fetchedAt time.Time
+}
+
+func (p *cachingCredentialProvider) GetCredentials(ctx context.Context) (Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.fetchedAt.IsZero() && time.Since(p.fetchedAt) < p.ttl {
+		return p.creds, nil
+	}
+
+	creds, err := p.provider.GetCredentials(ctx)
+	if err != nil {
+		return Credentials{}, err
+	}
+	p.creds = creds
+	p.fetchedAt = time.Now()
+
+	return creds, nil
+}
+
+// copy of the Connection with its credentials from CredentialProvider, if set
+func (c Connection) withCredentials(ctx context.Context) (Connection, error) {
+	if c.CredentialProvider == nil {
+		return c, nil
+	}
+
+	creds, err := c.CredentialProvider.GetCredentials(ctx)
+	if err != nil {
+		return c, fmt.Errorf("unable to get credentials: %w", err)
+	}
+
+	c.Username = creds.Username
+	c.Password = creds.Password
+	c.AuthenticationToken = creds.Token
+
+	return c, nil
+}
+
+// credentials from a JSON secret, with the given keys (defaulting to username, password and token)
+func secretCredentials(secret map[string]interface{}, usernameKey, passwordKey, tokenKey string) Credentials {
+	get := func(key, def string) string {
+		if key == "" {
+			key = def
+		}
+		s, _ := secret[key].(string)
+		return s
+	}
+
+	return Credentials{
+		Username: get(usernameKey, "username"),
+		Password: get(passwordKey, "password"),
+		Token:    get(tokenKey, "token"),
+	}
+}
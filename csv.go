@@ -0,0 +1,274 @@
+package go_splunk_rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+)
+
+// options controlling how search results are written out as CSV
+type CSVOptions struct {
+	// explicit ordered list of fields to emit, when empty SearchToCSV uses
+	// the fields listed by splunk, and WriteResultsCSV derives the columns
+	// from the results (sorted by field name)
+	Columns []string
+
+	// header label to use for a field instead of the field name
+	HeaderAliases map[string]string
+}
+
+// Run a blocking Search and write the results as CSV to w
+func (c Connection) SearchToCSV(searchQuery string, searchOptions SearchOptions, w io.Writer, csvOptions CSVOptions) error {
+	result, err := c.SearchDetailed(searchQuery, searchOptions)
+	if err != nil {
+		return err
+	}
+
+	if len(csvOptions.Columns) == 0 {
+		// keep splunk's column order
+		csvOptions.Columns = result.Fields
+	}
+
+	return WriteResultsCSV(w, result.Results, csvOptions)
+}
+
+// Write results as CSV to w, with a header row.
+// fields missing from a result are written as empty cells
+func WriteResultsCSV(w io.Writer, results []map[string]interface{}, csvOptions CSVOptions) error {
+	columns := csvOptions.Columns
+	if len(columns) == 0 {
+		columns = resultColumns(results)
+	}
+
+	csvWriter := csv.NewWriter(w)
+
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col
+		if alias, ok := csvOptions.HeaderAliases[col]; ok {
+			header[i] = alias
+		}
+	}
+	if err := csvWriter.Write(header); err != nil {
+		return fmt.Errorf("unable to write csv header: %s", err)
+	}
+
+	row := make([]string, len(columns))
+	for _, res := range results {
+		for i, col := range columns {
+			row[i] = csvValue(res[col])
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return fmt.Errorf("unable to write csv row: %s", err)
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// Get results of a search job with output_mode=csv, as rows of cells
+// (the first row is the header), which is cheaper to decode than json for wide results.
+// with ResultTransforms, the rows are rebuilt from the transformed results
+func (c Connection) SearchJobResultsCSV(jobID string, resultsOptions ResultsOptions) ([][]string, error) {
+	return c.SearchJobResultsCSVContext(context.Background(), jobID, resultsOptions)
+}
+
+func (c Connection) SearchJobResultsCSVContext(ctx context.Context, jobID string, resultsOptions ResultsOptions) ([][]string, error) {
+	data := resultsOptions.values()
+	data.Set("output_mode", "csv")
+
+	resp, respCode, err := c.httpCall(ctx, "GET", c.servicePath(fmt.Sprintf("/search/jobs/%s/results?%s", jobID, data.Encode())), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return [][]string{}, fmt.Errorf("unable to get search job results %w %d %s", err, respCode, string(resp))
+	}
+
+	if len(resp) == 0 {
+		// no results
+		return [][]string{}, nil
+	}
+
+	rows, err := csv.NewReader(bytes.NewReader(resp)).ReadAll()
+	if err != nil {
+		return [][]string{}, fmt.Errorf("unable to parse csv results from splunk: %s", err)
+	}
+	if len(c.ResultTransforms) == 0 || len(rows) == 0 {
+		return rows, nil
+	}
+
+	records := make([][]interface{}, len(rows)-1)
+	for i, row := range rows[1:] {
+		records[i] = csvRecord(row)
+	}
+	fields, records, err := c.transformRows(rows[0], records)
+	if err != nil {
+		return [][]string{}, err
+	}
+
+	rows = make([][]string, 0, len(records)+1)
+	rows = append(rows, fields)
+	for _, record := range records {
+		row := make([]string, len(record))
+		for i, v := range record {
+			row[i] = csvValue(v)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// csv row as values for transformRows
+func csvRecord(row []string) []interface{} {
+	record := make([]interface{}, len(row))
+	for i, v := range row {
+		record[i] = v
+	}
+
+	return record
+}
+
+// copy the csv export read from r to w, applying the Connection's ResultTransforms to every row.
+// the columns are those of the first row kept (see transformedFields), as they must be written
+// before the following rows are read, fields the transforms add to later rows only are left out
+func (c Connection) transformCSV(w io.Writer, r io.Reader) error {
+	csvReader := csv.NewReader(r)
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		// no results
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to parse csv export from splunk: %w", err)
+	}
+
+	csvWriter := csv.NewWriter(w)
+	var columns []string
+	for {
+		row, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("unable to parse csv export from splunk: %w", err)
+		}
+
+		res, keep, err := c.transformResult(rowResult(header, csvRecord(row)))
+		if err != nil {
+			return err
+		}
+		if !keep {
+			continue
+		}
+
+		if columns == nil {
+			columns = transformedFields(header, []map[string]interface{}{res})
+			if err := csvWriter.Write(columns); err != nil {
+				return fmt.Errorf("unable to write csv header: %s", err)
+			}
+		}
+
+		out := make([]string, len(columns))
+		for i, col := range columns {
+			out[i] = csvValue(res[col])
+		}
+		if err := csvWriter.Write(out); err != nil {
+			return fmt.Errorf("unable to write csv row: %s", err)
+		}
+	}
+
+	if columns == nil {
+		// every row was dropped, keep splunk's header
+		if err := csvWriter.Write(header); err != nil {
+			return fmt.Errorf("unable to write csv header: %s", err)
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// Run a search through the streaming export endpoint with output_mode=csv,
+// returning the response body to read the csv from. the caller must close it.
+// the csv is passed through as is, so this fails with ErrRawResults when the Connection
+// has ResultTransforms, use SearchExportTo with CSVFormat instead
+func (c Connection) SearchExportCSV(searchQuery string, searchOptions SearchOptions) (io.ReadCloser, error) {
+	return c.SearchExportCSVContext(context.Background(), searchQuery, searchOptions)
+}
+
+func (c Connection) SearchExportCSVContext(ctx context.Context, searchQuery string, searchOptions SearchOptions) (io.ReadCloser, error) {
+	if len(c.ResultTransforms) > 0 {
+		return nil, fmt.Errorf("unable to export search as csv: %w", ErrRawResults)
+	}
+
+	return c.exportCSV(ctx, searchQuery, searchOptions)
+}
+
+// run a search through the export endpoint with output_mode=csv, returning the raw response body
+func (c Connection) exportCSV(ctx context.Context, searchQuery string, searchOptions SearchOptions) (io.ReadCloser, error) {
+	data := searchOptions.values(searchQuery)
+	data.Del("max_count")
+	data.Set("output_mode", "csv")
+
+	headers := map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	}
+
+	resp, err := c.httpDo(ctx, "POST", c.servicePath("/search/jobs/export"), headers, []byte(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("unable to export search %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		respStr, _ := io.ReadAll(resp.Body)
+		if err := statusError(resp); err != nil {
+			return nil, fmt.Errorf("unable to export search: %w %s", err, string(respStr))
+		}
+		return nil, fmt.Errorf("unable to export search %d %s", resp.StatusCode, string(respStr))
+	}
+
+	return resp.Body, nil
+}
+
+// union of all field names present in results, sorted
+func resultColumns(results []map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	columns := []string{}
+	for _, res := range results {
+		for k := range res {
+			if !seen[k] {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	return columns
+}
+
+func csvValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case []interface{}:
+		// multivalue field, join values with a newline like splunk does
+		s := ""
+		for i, mv := range val {
+			if i > 0 {
+				s += "\n"
+			}
+			s += csvValue(mv)
+		}
+		return s
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
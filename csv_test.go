@@ -0,0 +1,81 @@
+package go_splunk_rest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestWriteResultsCSV(t *testing.T) {
+	results := []map[string]interface{}{
+		{"host": "web-1", "count": "3", "_time": "2024-01-01T00:00:00.000+00:00"},
+		{"host": "web-2", "_time": "2024-01-01T00:01:00.000+00:00"},
+	}
+
+	tests := []struct {
+		name       string
+		csvOptions CSVOptions
+		want       string
+	}{
+		{
+			name: "columns in the given order, aliased, missing fields empty",
+			csvOptions: CSVOptions{
+				Columns:       []string{"count", "host"},
+				HeaderAliases: map[string]string{"count": "Events"},
+			},
+			want: "Events,host\n3,web-1\n,web-2\n",
+		},
+		{
+			name: "columns derived from the results",
+			want: "_time,count,host\n" +
+				"2024-01-01T00:00:00.000+00:00,3,web-1\n" +
+				"2024-01-01T00:01:00.000+00:00,,web-2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := WriteResultsCSV(&buf, results, tt.csvOptions); err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestCSVResultTransforms(t *testing.T) {
+	c := newTestConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "user,email\nalice,alice@example.com\nbob,bob@example.com\n")
+	})
+	c.ResultTransforms = []ResultTransform{
+		func(res map[string]interface{}) (map[string]interface{}, error) {
+			res["email"] = "***"
+			return res, nil
+		},
+	}
+
+	rows, err := c.SearchJobResultsCSV("1234.5", ResultsOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"user", "email"}, {"alice", "***"}, {"bob", "***"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("got %v, want %v", rows, want)
+	}
+
+	var buf bytes.Buffer
+	if err := c.SearchExportTo("search index=main", SearchOptions{}, &buf, CSVFormat); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "user,email\nalice,***\nbob,***\n" {
+		t.Fatalf("got csv export %q, want the emails masked", got)
+	}
+
+	if _, err := c.SearchExportCSV("search index=main", SearchOptions{}); !errors.Is(err, ErrRawResults) {
+		t.Fatalf("got %v, want ErrRawResults", err)
+	}
+}
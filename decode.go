@@ -0,0 +1,200 @@
+package go_splunk_rest
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// options controlling how DecodeResults maps splunk fields onto struct fields
+type DecodeOptions struct {
+	// struct tag holding the splunk field name, defaults to "json".
+	// fields without the tag are matched by their Go name, fields tagged "-" are skipped
+	TagName string
+
+	// match field names ignoring case
+	CaseInsensitive bool
+
+	// match field names ignoring '.', '_' and '-',
+	// so "src.ip" and "src_ip" both map to a SrcIP (or src_ip tagged) field
+	IgnoreSeparators bool
+}
+
+// Decode results into dst, which must be a pointer to a slice of structs
+// (or of pointers to structs). string values are converted to the kind of the
+// struct field (string, bool, ints, uints, floats, time.Time using ParseTime), multivalue fields
+// can be decoded into slices, or into a scalar field which takes the first value.
+// result fields without a matching struct field are ignored
+func DecodeResults(results []map[string]interface{}, dst interface{}, decodeOptions DecodeOptions) error {
+	dstVal := reflect.ValueOf(dst)
+	if dstVal.Kind() != reflect.Pointer || dstVal.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("unable to decode results into %T: must be a pointer to a slice", dst)
+	}
+	sliceVal := dstVal.Elem()
+
+	elemType := sliceVal.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("unable to decode results into %T: slice elements must be structs", dst)
+	}
+
+	fieldIndex := decodeFieldIndex(structType, decodeOptions)
+
+	decoded := reflect.MakeSlice(sliceVal.Type(), 0, len(results))
+	for i, res := range results {
+		structVal := reflect.New(structType).Elem()
+
+		for k, v := range res {
+			idx, ok := fieldIndex[decodeOptions.normalize(k)]
+			if !ok {
+				continue
+			}
+
+			if err := setDecodedValue(structVal.Field(idx), v); err != nil {
+				return fmt.Errorf("unable to decode field %s of result %d: %s", k, i, err)
+			}
+		}
+
+		if elemType.Kind() == reflect.Pointer {
+			decoded = reflect.Append(decoded, structVal.Addr())
+		} else {
+			decoded = reflect.Append(decoded, structVal)
+		}
+	}
+
+	sliceVal.Set(decoded)
+	return nil
+}
+
+func (o DecodeOptions) normalize(name string) string {
+	if o.CaseInsensitive {
+		name = strings.ToLower(name)
+	}
+	if o.IgnoreSeparators {
+		name = strings.NewReplacer(".", "", "_", "", "-", "").Replace(name)
+	}
+
+	return name
+}
+
+// normalized splunk field name -> struct field index
+func decodeFieldIndex(structType reflect.Type, decodeOptions DecodeOptions) map[string]int {
+	tagName := decodeOptions.TagName
+	if tagName == "" {
+		tagName = "json"
+	}
+
+	fieldIndex := make(map[string]int)
+	for i := 0; i < structType.NumField(); i++ {
+		f := structType.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name := f.Name
+		if tag, ok := f.Tag.Lookup(tagName); ok {
+			tag, _, _ = strings.Cut(tag, ",")
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+
+		fieldIndex[decodeOptions.normalize(name)] = i
+	}
+
+	return fieldIndex
+}
+
+func setDecodedValue(field reflect.Value, v interface{}) error {
+	if v == nil {
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.Interface:
+		field.Set(reflect.ValueOf(v))
+		return nil
+	case reflect.Pointer:
+		ptr := reflect.New(field.Type().Elem())
+		if err := setDecodedValue(ptr.Elem(), v); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	case reflect.Slice:
+		values, ok := v.([]interface{})
+		if !ok {
+			values = []interface{}{v}
+		}
+
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, mv := range values {
+			if err := setDecodedValue(slice.Index(i), mv); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	// scalar field, take the first value of a multivalue field
+	if values, ok := v.([]interface{}); ok {
+		if len(values) == 0 {
+			return nil
+		}
+		v = values[0]
+	}
+	s := fmt.Sprintf("%v", v)
+
+	if field.Type() == timeType {
+		t, err := ParseTime(s)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Run a search through the streaming export endpoint, calling handler for each result
+// as it is decoded from the response, instead of holding all results in memory.
+// export searches are not limited by max_count; returning an error from handler
+// stops the export and is returned as is
+// more details can be found here:
+// https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTsearch#search.2Fjobs.2Fexport
+func (c Connection) SearchExport(searchQuery string, searchOptions SearchOptions, handler func(map[string]interface{}) error) error {
+	return c.SearchExportContext(context.Background(), searchQuery, searchOptions, handler)
+}
+
+func (c Connection) SearchExportContext(ctx context.Context, searchQuery string, searchOptions SearchOptions, handler func(map[string]interface{}) error) error {
+	data := searchOptions.values(searchQuery)
+	data.Del("max_count")
+
+	headers := map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	}
+
+	resp, err := c.httpDo(ctx, "POST", c.servicePath("/search/jobs/export"), headers, []byte(data.Encode()))
+	if err != nil {
+		return fmt.Errorf("unable to export search %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respStr, _ := io.ReadAll(resp.Body)
+		if err := statusError(resp); err != nil {
+			return fmt.Errorf("unable to export search: %w %s", err, string(respStr))
+		}
+		return fmt.Errorf("unable to export search %d %s", resp.StatusCode, string(respStr))
+	}
+
+	// the export response is a stream of json objects, one per result
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		row := struct {
+			Preview  bool                   `json:"preview"`
+			Result   map[string]interface{} `json:"result"`
+			Messages []SearchMessage        `json:"messages"`
+		}{}
+
+		err := decoder.Decode(&row)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unable to parse export results from splunk: %w", err)
+		}
+
+		for _, m := range row.Messages {
+			if m.Type == "FATAL" || m.Type == "ERROR" {
+				return fmt.Errorf("%s: %s", m.Type, m.Message)
+			}
+		}
+
+		if row.Preview || row.Result == nil {
+			continue
+		}
+
+		res, keep, err := c.transformResult(row.Result)
+		if err != nil {
+			return err
+		}
+		if !keep {
+			continue
+		}
+
+		if err := handler(res); err != nil {
+			return err
+		}
+	}
+}
+
+type Format string
+
+const JSONLFormat Format = "jsonl" // one json object per result, per line
+const CSVFormat Format = "csv"     // csv as produced by splunk (rewritten with ResultTransforms), with a header row
+
+// Run a search through the streaming export endpoint, writing the results to w
+// in format as they are received, without holding them in memory
+func (c Connection) SearchExportTo(searchQuery string, searchOptions SearchOptions, w io.Writer, format Format) error {
+	return c.SearchExportToContext(context.Background(), searchQuery, searchOptions, w, format)
+}
+
+func (c Connection) SearchExportToContext(ctx context.Context, searchQuery string, searchOptions SearchOptions, w io.Writer, format Format) error {
+	switch format {
+	case JSONLFormat:
+		encoder := json.NewEncoder(w)
+		return c.SearchExportContext(ctx, searchQuery, searchOptions, func(res map[string]interface{}) error {
+			return encoder.Encode(res)
+		})
+	case CSVFormat:
+		body, err := c.exportCSV(ctx, searchQuery, searchOptions)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+
+		if len(c.ResultTransforms) > 0 {
+			return c.transformCSV(w, body)
+		}
+		if _, err := io.Copy(w, body); err != nil {
+			return fmt.Errorf("unable to write csv export: %w", err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unsupported export format: %s", format)
+}
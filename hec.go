@@ -0,0 +1,101 @@
+package go_splunk_rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// an event sent to the HTTP Event Collector, empty metadata fields
+// are left to the HEC token's defaults
+type HECEvent struct {
+	Time       time.Time // defaults to the time splunk receives the event
+	Host       string
+	Source     string
+	Sourcetype string
+	Index      string
+
+	Event  interface{}            // string, or anything marshalling to a JSON object
+	Fields map[string]interface{} // indexed fields
+}
+
+func (e HECEvent) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{
+		"event": e.Event,
+	}
+	if !e.Time.IsZero() {
+		m["time"] = float64(e.Time.UnixNano()) / float64(time.Second)
+	}
+	if e.Host != "" {
+		m["host"] = e.Host
+	}
+	if e.Source != "" {
+		m["source"] = e.Source
+	}
+	if e.Sourcetype != "" {
+		m["sourcetype"] = e.Sourcetype
+	}
+	if e.Index != "" {
+		m["index"] = e.Index
+	}
+	if len(e.Fields) > 0 {
+		m["fields"] = e.Fields
+	}
+
+	return json.Marshal(m)
+}
+
+// Connection sending to the Connection's HTTP Event Collector, at HECHost (defaulting to Host)
+// authenticated with HECToken instead of the REST authentication
+func (c Connection) hecConnection() Connection {
+	hec := c
+	if c.HECHost != "" {
+		hec.Host = c.HECHost
+	}
+	// the HEC token is set as a header, REST credentials are not sent to the collector
+	hec.AuthType = ""
+	hec.AuthFallback = nil
+	hec.CredentialProvider = nil
+
+	return hec
+}
+
+// Send events to the HTTP Event Collector in a single request, with the Connection's HECToken
+// more details can be found here:
+// https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTinput#services.2Fcollector.2Fevent
+func (c Connection) HECSend(events []HECEvent) error {
+	return c.HECSendContext(context.Background(), events)
+}
+
+func (c Connection) HECSendContext(ctx context.Context, events []HECEvent) error {
+	if c.HECToken == "" {
+		return fmt.Errorf("no HEC token")
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	// HEC takes concatenated JSON events
+	var data bytes.Buffer
+	enc := json.NewEncoder(&data)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("unable to encode HEC event: %s", err)
+		}
+	}
+
+	headers := map[string]string{
+		"Authorization": "Splunk " + c.HECToken,
+		"Content-Type":  "application/json",
+	}
+
+	resp, respCode, err := c.hecConnection().httpCall(ctx, "POST", "/services/collector/event", headers, data.Bytes())
+	if err != nil || respCode != http.StatusOK {
+		return fmt.Errorf("unable to send HEC events %w %d %s", err, respCode, string(resp))
+	}
+
+	return nil
+}
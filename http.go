@@ -2,69 +2,508 @@ package go_splunk_rest
 
 import (
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
 	log "log/slog"
 )
 
-func (c Connection) httpCall(method, endpoint string, headers map[string]string, data []byte) ([]byte, int, error) {
+// returned (wrapped) when a response exceeds Connection.MaxResponseSize
+// or a search exceeds Connection.MaxResults
+var ErrResponseTooLarge = errors.New("response too large")
+
+// returned (wrapped) when splunk rejects a request's credentials (401),
+// or the authenticated user isn't allowed to make it (403)
+var ErrUnauthorized = errors.New("unauthorized")
+var ErrForbidden = errors.New("forbidden")
+
+// returned (wrapped) when splunkd throttles a request (429, or 503 with Retry-After)
+// and it isn't retried, or is throttled again on every retry
+var ErrThrottled = errors.New("throttled")
+
+const RETRY_WAIT = 1
+const HTTP_TIMEOUT = 90 // seconds allowed for a (non streaming) request, including reading the response
+
+// send a request to splunk, retrying transient failures,
+// the request (and any retry wait) is aborted when ctx is done
+func (c Connection) httpCall(ctx context.Context, method, endpoint string, headers map[string]string, data []byte) ([]byte, int, error) {
+	// the body isn't logged, it may carry credentials (login, password changes, ...)
 	log.Debug("httpCall",
 		"method", method,
 		"endpoint", endpoint,
-		"headers", headers,
-		"data", data)
+		"headers", redactHeaders(headers),
+		"size", len(data))
+
+	for attempt := 0; ; attempt++ {
+		respStr, resp, err := c.httpRoundTrip(ctx, method, endpoint, headers, data)
+
+		retry := attempt < c.RetryMaxAttempts && ctx.Err() == nil &&
+			!errors.Is(err, ErrResponseTooLarge) && c.isRetryable(method, resp, err) && c.takeRetry()
+
+		var wait time.Duration
+		if retry {
+			wait = c.retryBackoff(attempt)
+		}
+
+		if retryAfter, ok := throttled(resp); ok {
+			// wait as long as splunkd asks, up to the max backoff
+			if retry && retryAfter > 0 {
+				wait = min(retryAfter, c.retryMaxWait())
+			}
+			if c.OnThrottle != nil {
+				c.OnThrottle(Throttle{
+					Method:     method,
+					Endpoint:   endpoint,
+					StatusCode: resp.StatusCode,
+					RetryAfter: retryAfter,
+					Retrying:   retry,
+					Wait:       wait,
+					Attempt:    attempt + 1,
+				})
+			}
+		}
+
+		if retry {
+			log.Warn("httpCall failed, retrying",
+				"method", method,
+				"endpoint", endpoint,
+				"attempt", attempt+1,
+				"wait", wait,
+				"err", err)
+			select {
+			case <-ctx.Done():
+				return []byte(""), 0, ctx.Err()
+			case <-time.After(wait):
+			}
+			continue
+		}
+
+		if err != nil {
+			return []byte(""), 0, err
+		}
+
+		if err := statusError(resp); err != nil {
+			return respStr, resp.StatusCode, fmt.Errorf("%s %s: %w", method, endpoint, err)
+		}
+
+		return respStr, resp.StatusCode, nil
+	}
+}
+
+// copy of headers safe to log, with credentials (e.g. the HEC token) masked
+func redactHeaders(headers map[string]string) map[string]string {
+	redacted := make(map[string]string, len(headers))
+	for h, v := range headers {
+		switch http.CanonicalHeaderKey(h) {
+		case "Authorization", "Proxy-Authorization", "Cookie":
+			v = "REDACTED"
+		}
+		redacted[h] = v
+	}
+
+	return redacted
+}
 
+// ErrUnauthorized or ErrForbidden for 401 and 403 responses,
+// ErrThrottled for throttled ones, nil otherwise
+func statusError(resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	}
+	if _, ok := throttled(resp); ok {
+		return ErrThrottled
+	}
+
+	return nil
+}
+
+// send a single http request and read the whole response body, within HTTP_TIMEOUT
+func (c Connection) httpRoundTrip(ctx context.Context, method, endpoint string, headers map[string]string, data []byte) ([]byte, *http.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, HTTP_TIMEOUT*time.Second)
+	defer cancel()
+
+	resp, err := c.httpDo(ctx, method, endpoint, headers, data)
+	if err != nil {
+		return []byte(""), nil, err
+	}
+	defer resp.Body.Close()
+
+	body := io.Reader(resp.Body)
+	if c.MaxResponseSize > 0 {
+		body = io.LimitReader(resp.Body, c.MaxResponseSize+1)
+	}
+
+	respStr, err := io.ReadAll(body)
+	if err != nil {
+		return []byte(""), nil, err
+	}
+
+	if c.MaxResponseSize > 0 && int64(len(respStr)) > c.MaxResponseSize {
+		return []byte(""), nil, fmt.Errorf("%s %s: %w (more than %d bytes)", method, endpoint, ErrResponseTooLarge, c.MaxResponseSize)
+	}
+
+	return respStr, resp, nil
+}
+
+// build and send a single http request, the caller is responsible for closing the response body.
+// no timeout is applied besides ctx, so it can be used for streaming responses.
+// if authenticating fails (or is rejected with a 401), the request is sent again
+// with each of the AuthFallback authentication types in turn
+func (c Connection) httpDo(ctx context.Context, method, endpoint string, headers map[string]string, data []byte) (*http.Response, error) {
+	authTypes := append([]AuthenticationType{c.AuthType}, c.AuthFallback...)
+
+	for i, authType := range authTypes {
+		authConn := c
+		authConn.AuthType = authType
+
+		resp, err := authConn.httpDoAuth(ctx, method, endpoint, headers, data)
+		if err == nil && resp.StatusCode == http.StatusUnauthorized && authType == AuthorizationTokenAuth {
+			// the session expired (or was logged out) mid-flight, log in again and retry once
+			resp.Body.Close()
+			log.Info("session key rejected, logging in again", "endpoint", endpoint)
+			authConn.invalidateSession(ctx, strings.TrimPrefix(resp.Request.Header.Get("Authorization"), "Splunk "))
+
+			resp, err = authConn.httpDoAuth(ctx, method, endpoint, headers, data)
+		}
+		if tokenSource, ok := c.TokenSource.(InvalidatingTokenSource); ok &&
+			err == nil && resp.StatusCode == http.StatusUnauthorized && authType == AuthenticationTokenAuth {
+			// the token was revoked (or expired early), get a new one and retry once
+			resp.Body.Close()
+			log.Info("token rejected, refreshing it", "endpoint", endpoint)
+			tokenSource.Invalidate()
+
+			resp, err = authConn.httpDoAuth(ctx, method, endpoint, headers, data)
+		}
+		if i == len(authTypes)-1 || ctx.Err() != nil {
+			return resp, err
+		}
+
+		var authErr *authSetupError
+		if err != nil && !errors.As(err, &authErr) {
+			return resp, err
+		}
+		if err == nil && resp.StatusCode != http.StatusUnauthorized {
+			return resp, err
+		}
+
+		if err == nil {
+			resp.Body.Close()
+			err = fmt.Errorf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+		}
+		log.Warn("authentication failed, falling back",
+			"auth-type", authType,
+			"fallback", authTypes[i+1],
+			"endpoint", endpoint,
+			"err", err)
+	}
+
+	// unreachable, authTypes is never empty
+	return nil, fmt.Errorf("no authentication type")
+}
+
+// failure to authenticate a request before sending it (e.g. login or token refresh failed)
+type authSetupError struct {
+	err error
+}
+
+func (e *authSetupError) Error() string {
+	return e.err.Error()
+}
+
+func (e *authSetupError) Unwrap() error {
+	return e.err
+}
+
+// send a single http request authenticated with the Connection's AuthType
+func (c Connection) httpDoAuth(ctx context.Context, method, endpoint string, headers map[string]string, data []byte) (*http.Response, error) {
 	url := fmt.Sprintf("%s%s", c.Host, endpoint)
 
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(data))
+	// tunnel verbs blocked by restrictive proxies through POST
+	overrideMethod := ""
+	if c.MethodOverride && (method == "DELETE" || method == "PUT") {
+		overrideMethod = method
+		method = "POST"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(data))
 	if err != nil {
-		return []byte(""), 0, err
+		return nil, err
 	}
 
 	// Wrap Auth based on Connection Authentication Type
 	err = c.wrapAuth(req)
 	if err != nil {
-		return []byte(""), 0, err
+		return nil, &authSetupError{err: err}
 	}
 
 	// Set Headers
 	for h, v := range headers {
 		req.Header.Set(h, v)
 	}
+	if overrideMethod != "" {
+		req.Header.Set("X-HTTP-Method-Override", overrideMethod)
+	}
+	if c.HTTPClient == nil && c.Transport == nil && req.URL.Scheme == "http" {
+		// plain http requests are sent to the proxy as is, authenticate them to it
+		if proxyURL, _ := c.proxy()(req); proxyURL != nil && proxyURL.Scheme != "socks5" {
+			for h, v := range c.proxyHeaders() {
+				req.Header[h] = v
+			}
+		}
+	}
 
-	client := buildHttpClient()
-
-	resp, err := client.Do(req)
+	client, err := c.buildHttpClient()
 	if err != nil {
-		return []byte(""), 0, err
+		return nil, err
+	}
+
+	return client.Do(req)
+}
+
+// decide if a failed http call should be retried,
+// Connection.RetryClassifier overrides the default of retrying GET and HEAD requests
+// on transport errors and 429/502/503/504 responses. other requests (e.g. dispatching
+// a search, sending HEC events) may have been acted on, so they are only retried when
+// they were never sent, such as when connecting failed, or were throttled by splunkd
+// (429, or 503 with Retry-After), which refuses them without acting on them.
+// a request whose login splunkd rejected is never retried.
+// the response body has already been consumed at this point
+func (c Connection) isRetryable(method string, resp *http.Response, err error) bool {
+	if c.RetryClassifier != nil {
+		return c.RetryClassifier(resp, err)
+	}
+
+	if notSent(err) {
+		return true
+	}
+	var authErr *authSetupError
+	if errors.As(err, &authErr) {
+		// splunkd rejected the login (or token refresh), retrying could lock the account out
+		return false
+	}
+	if _, ok := throttled(resp); ok {
+		return true
+	}
+	if method != "GET" && method != "HEAD" {
+		return false
 	}
-	defer resp.Body.Close()
 
-	respStr, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return []byte(""), 0, err
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
 	}
 
-	return respStr, resp.StatusCode, nil
+	return false
 }
 
-func buildHttpClient() *http.Client {
+// whether err happened before the request was written, so splunk never saw it
+func notSent(err error) bool {
+	var opErr *net.OpError
+	var authErr *authSetupError
+	if errors.As(err, &authErr) {
+		// logging in (or refreshing the token) failed before the request was sent,
+		// which can only be retried when splunkd couldn't be reached
+		return errors.As(authErr.err, &opErr)
+	}
+
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// transports of the Connections, keyed by transportKey.
+// shared by copies of a Connection, as Connection is passed by value,
+// so their requests reuse pooled connections and TLS sessions
+var transports sync.Map
+
+type cachedTransport struct {
+	transport *http.Transport
+	files     string // filesStamp of the certificate files it was built with
+}
+
+// random per process, so secrets in cache keys can't be recovered from them
+var secretSalt = func() []byte {
+	salt := make([]byte, 32)
+	rand.Read(salt)
+	return salt
+}()
+
+// fingerprint of a secret to use in a cache key
+func secretFingerprint(secret string) string {
+	mac := hmac.New(sha256.New, secretSalt)
+	mac.Write([]byte(secret))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// everything the transport is built from, Connections with the same key share a transport
+func (c Connection) transportKey() string {
+	// the proxy url may carry credentials too
+	return fmt.Sprintf("%s|%t|%s|%s|%s|%s|%s|%t|%s",
+		c.Host, c.DisableCompression,
+		c.ClientCertFile, c.ClientKeyFile, c.CACertFile,
+		c.MinTLSVersion, c.TLSServerName, c.InsecureSkipVerify,
+		secretFingerprint(fmt.Sprintf("%s|%s|%s|%v", c.ProxyURL, c.ProxyUsername, c.ProxyPassword, c.ProxyHeaders)))
+}
+
+// modification time and size of the certificate files, so the transport
+// is rebuilt (reloading them) when a certificate or CA bundle is rotated
+func (c Connection) filesStamp() string {
+	stamp := ""
+	for _, f := range []string{c.ClientCertFile, c.ClientKeyFile, c.CACertFile} {
+		if f == "" {
+			continue
+		}
+		if info, err := os.Stat(f); err == nil {
+			stamp += fmt.Sprintf("%s:%d:%d|", f, info.ModTime().UnixNano(), info.Size())
+		}
+	}
+
+	return stamp
+}
+
+// the Connection's transport, built on first use and
+// again whenever its certificate files change
+func (c Connection) transport() (*http.Transport, error) {
+	key := c.transportKey()
+	files := c.filesStamp()
+	if v, ok := transports.Load(key); ok && v.(*cachedTransport).files == files {
+		return v.(*cachedTransport).transport, nil
+	}
+
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	netTransport := &http.Transport{
-		Dial: (&net.Dialer{
+		// request gzip compressed responses and transparently decompress them
+		DisableCompression: c.DisableCompression,
+		DialContext: (&net.Dialer{
 			Timeout:   90 * time.Second,
 			KeepAlive: 60 * time.Second,
-		}).Dial,
+		}).DialContext,
 		TLSHandshakeTimeout: 30 * time.Second,
-		// 	TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // uncomment line to disable TLS verification (Not Recommended)
+		TLSClientConfig:     tlsConfig,
+		Proxy:               c.proxy(),
+		// proxy authentication for https requests, tunnelled with CONNECT
+		ProxyConnectHeader: c.proxyHeaders(),
+		// keep enough idle connections for partitioned searches polling concurrently
+		MaxIdleConnsPerHost: 16,
+		IdleConnTimeout:     90 * time.Second,
 	}
+
+	// replaces the transport built with the previous certificate files, if any.
+	// requests racing to build it may each use their own, that's harmless
+	if old, ok := transports.Swap(key, &cachedTransport{transport: netTransport, files: files}); ok {
+		old.(*cachedTransport).transport.CloseIdleConnections()
+	}
+
+	return netTransport, nil
+}
+
+func (c Connection) buildHttpClient() (*http.Client, error) {
+	if c.HTTPClient != nil {
+		client := *c.HTTPClient
+		if client.Jar == nil {
+			client.Jar = c.cookieJar()
+		}
+		return &client, nil
+	}
+
+	var netTransport http.RoundTripper = c.Transport
+	if netTransport == nil {
+		t, err := c.transport()
+		if err != nil {
+			return nil, err
+		}
+		netTransport = t
+	}
+
+	// no client Timeout, it would also cut off streaming responses,
+	// httpCall bounds each request with HTTP_TIMEOUT instead.
+	// the client itself is cheap, connections are pooled by the shared transport
 	client := &http.Client{
-		Timeout:   time.Second * 90,
 		Transport: netTransport,
+		Jar:       c.cookieJar(),
+	}
+
+	return client, nil
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLS config with the Connection's client certificate, CA bundle and TLS options, nil for the defaults
+func (c Connection) tlsConfig() (*tls.Config, error) {
+	if c.ClientCertFile == "" && c.CACertFile == "" &&
+		c.MinTLSVersion == "" && c.TLSServerName == "" && !c.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: c.TLSServerName,
+	}
+
+	if c.InsecureSkipVerify {
+		log.Warn("TLS certificate verification disabled", "host", c.Host)
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	if c.MinTLSVersion != "" {
+		version, ok := tlsVersions[c.MinTLSVersion]
+		if !ok {
+			return nil, fmt.Errorf("invalid min TLS version: %s, must be one of 1.0, 1.1, 1.2, 1.3", c.MinTLSVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if c.ClientCertFile != "" {
+		keyFile := c.ClientKeyFile
+		if keyFile == "" {
+			// key bundled with the certificate
+			keyFile = c.ClientCertFile
+		}
+		cert, err := tls.LoadX509KeyPair(c.ClientCertFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate %s: %s", c.ClientCertFile, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if c.CACertFile != "" {
+		pem, err := os.ReadFile(c.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA bundle %s: %s", c.CACertFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", c.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
 	}
 
-	return client
+	return tlsConfig, nil
 }
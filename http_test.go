@@ -0,0 +1,91 @@
+package go_splunk_rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMethodOverride(t *testing.T) {
+	var method, override string
+	c := newTestConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		override = r.Header.Get("X-HTTP-Method-Override")
+	})
+	c.MethodOverride = true
+
+	if err := c.SearchJobDelete("1234.5"); err != nil {
+		t.Fatal(err)
+	}
+	if method != "POST" || override != "DELETE" {
+		t.Fatalf("got %s with X-HTTP-Method-Override %q, want POST with DELETE", method, override)
+	}
+}
+
+func TestRetryClassifier(t *testing.T) {
+	calls := 0
+	c := newTestConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(520)
+			return
+		}
+		fmt.Fprint(w, `{"entry": [{"content": {"sid": "1234.5", "isDone": true}}]}`)
+	})
+	c.RetryMaxAttempts = 1
+	c.RetryWait = time.Millisecond
+
+	if _, err := c.SearchJobStatus("1234.5"); err == nil {
+		t.Fatal("expected 520 to fail without a RetryClassifier")
+	}
+
+	calls = 0
+	c.RetryClassifier = func(resp *http.Response, err error) bool {
+		return err == nil && resp.StatusCode == 520
+	}
+	status, err := c.SearchJobStatus("1234.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 || status.Content().Sid != "1234.5" {
+		t.Fatalf("got %d calls and sid %q, want the 520 retried once", calls, status.Content().Sid)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	calls := 0
+	c := newTestConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	})
+	var throttles []Throttle
+	c.OnThrottle = func(th Throttle) {
+		throttles = append(throttles, th)
+	}
+
+	err := c.SearchJobControl("1234.5", "touch", nil)
+	if !errors.Is(err, ErrThrottled) {
+		t.Fatalf("got %v, want ErrThrottled with retries disabled", err)
+	}
+	if calls != 1 || len(throttles) != 1 || throttles[0].Retrying {
+		t.Fatalf("got %d calls and throttles %+v, want one throttle not retried", calls, throttles)
+	}
+
+	// a throttled POST is retried too, splunkd didn't act on it
+	calls, throttles = 0, nil
+	c.RetryMaxAttempts = 1
+	c.RetryMaxWait = time.Millisecond
+	if err := c.SearchJobControl("1234.5", "touch", nil); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 || len(throttles) != 1 || !throttles[0].Retrying || throttles[0].Wait != time.Millisecond {
+		t.Fatalf("got %d calls and throttles %+v, want one throttle retried after RetryMaxWait", calls, throttles)
+	}
+}
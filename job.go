@@ -0,0 +1,88 @@
+package go_splunk_rest
+
+import (
+	"context"
+)
+
+// handle on a dispatched search job, carrying the sid and the Connection it was dispatched on
+type SearchJob struct {
+	Sid string
+
+	conn          Connection
+	searchOptions SearchOptions
+}
+
+// Get a handle on an existing search job,
+// e.g. one dispatched by SavedSearchDispatch or before a restart
+func (c Connection) SearchJobOpen(jobID string) *SearchJob {
+	return &SearchJob{
+		Sid:  jobID,
+		conn: c,
+	}
+}
+
+// Block until the job is done, polling with the poll options (and callbacks)
+// it was started with. the job is left running if ctx is done first
+func (j *SearchJob) Wait(ctx context.Context) error {
+	return j.conn.waitSearchJob(ctx, j.Sid, j.searchOptions, false)
+}
+
+func (j *SearchJob) Status() (SearchJobStatus, error) {
+	return j.StatusContext(context.Background())
+}
+
+func (j *SearchJob) StatusContext(ctx context.Context) (SearchJobStatus, error) {
+	return j.conn.SearchJobStatusContext(ctx, j.Sid)
+}
+
+// Get all output of the job, the job should be done (see Wait).
+// for transforming searches these are the results, for event searches the events
+func (j *SearchJob) Results() ([]map[string]interface{}, error) {
+	return j.ResultsContext(context.Background())
+}
+
+func (j *SearchJob) ResultsContext(ctx context.Context) ([]map[string]interface{}, error) {
+	jobStatus, err := j.StatusContext(ctx)
+	if err != nil {
+		return []map[string]interface{}{}, err
+	}
+
+	if jobStatus.IsTransforming() {
+		return j.conn.SearchJobResultsAllContext(ctx, j.Sid)
+	}
+
+	eventCount := jobStatus.Content().EventCount
+	events := make([]map[string]interface{}, 0, eventCount)
+	for offset := 0; offset < eventCount; offset += RESULTS_PAGE_SIZE {
+		page, err := j.conn.searchJobEvents(ctx, j.Sid, ResultsOptions{
+			Count:  RESULTS_PAGE_SIZE,
+			Offset: offset,
+		})
+		if err != nil {
+			return events, err
+		}
+
+		events = append(events, page...)
+		if len(page) < RESULTS_PAGE_SIZE {
+			break
+		}
+	}
+
+	return j.conn.transformResults(events)
+}
+
+func (j *SearchJob) Cancel() error {
+	return j.CancelContext(context.Background())
+}
+
+func (j *SearchJob) CancelContext(ctx context.Context) error {
+	return j.conn.SearchJobControlContext(ctx, j.Sid, "cancel", nil)
+}
+
+func (j *SearchJob) Delete() error {
+	return j.DeleteContext(context.Background())
+}
+
+func (j *SearchJob) DeleteContext(ctx context.Context) error {
+	return j.conn.SearchJobDeleteContext(ctx, j.Sid)
+}
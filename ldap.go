@@ -0,0 +1,239 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// an LDAP authentication strategy
+// more details can be found here:
+// https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTaccess#authentication.2Fproviders.2FLDAP
+type LDAPStrategy struct {
+	Name string
+
+	Host       string
+	Port       int
+	SSLEnabled bool
+	Disabled   bool
+
+	BindDN         string
+	BindDNPassword string // write only, never returned by splunk
+
+	UserBaseDN        string
+	UserBaseFilter    string
+	UserNameAttribute string
+	RealNameAttribute string
+	EmailAttribute    string
+
+	GroupBaseDN           string
+	GroupBaseFilter       string
+	GroupMemberAttribute  string
+	GroupNameAttribute    string
+	GroupMappingAttribute string
+	NestedGroups          bool
+}
+
+func (s LDAPStrategy) values() url.Values {
+	data := make(url.Values)
+	data.Add("output_mode", "json")
+
+	set := func(k, v string) {
+		if v != "" {
+			data.Add(k, v)
+		}
+	}
+	boolStr := func(b bool) string {
+		if b {
+			return "1"
+		}
+		return "0"
+	}
+
+	set("host", s.Host)
+	if s.Port > 0 {
+		data.Add("port", strconv.Itoa(s.Port))
+	}
+	data.Add("SSLEnabled", boolStr(s.SSLEnabled))
+	data.Add("disabled", boolStr(s.Disabled))
+	set("bindDN", s.BindDN)
+	set("bindDNpassword", s.BindDNPassword)
+	set("userBaseDN", s.UserBaseDN)
+	set("userBaseFilter", s.UserBaseFilter)
+	set("userNameAttribute", s.UserNameAttribute)
+	set("realNameAttribute", s.RealNameAttribute)
+	set("emailAttribute", s.EmailAttribute)
+	set("groupBaseDN", s.GroupBaseDN)
+	set("groupBaseFilter", s.GroupBaseFilter)
+	set("groupMemberAttribute", s.GroupMemberAttribute)
+	set("groupNameAttribute", s.GroupNameAttribute)
+	set("groupMappingAttribute", s.GroupMappingAttribute)
+	data.Add("nestedGroups", boolStr(s.NestedGroups))
+
+	return data
+}
+
+func ldapStrategyFromContent(name string, content map[string]interface{}) LDAPStrategy {
+	str := func(k string) string {
+		return contentString(content, k)
+	}
+	port, _ := strconv.Atoi(str("port"))
+
+	return LDAPStrategy{
+		Name:                  name,
+		Host:                  str("host"),
+		Port:                  port,
+		SSLEnabled:            contentBool(content, "SSLEnabled"),
+		Disabled:              contentBool(content, "disabled"),
+		BindDN:                str("bindDN"),
+		UserBaseDN:            str("userBaseDN"),
+		UserBaseFilter:        str("userBaseFilter"),
+		UserNameAttribute:     str("userNameAttribute"),
+		RealNameAttribute:     str("realNameAttribute"),
+		EmailAttribute:        str("emailAttribute"),
+		GroupBaseDN:           str("groupBaseDN"),
+		GroupBaseFilter:       str("groupBaseFilter"),
+		GroupMemberAttribute:  str("groupMemberAttribute"),
+		GroupNameAttribute:    str("groupNameAttribute"),
+		GroupMappingAttribute: str("groupMappingAttribute"),
+		NestedGroups:          contentBool(content, "nestedGroups"),
+	}
+}
+
+// value of a conf entry's content as a string, splunk returns numbers and booleans
+// either as JSON values or as strings depending on the endpoint
+func contentString(content map[string]interface{}, key string) string {
+	switch v := content[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func contentBool(content map[string]interface{}, key string) bool {
+	switch contentString(content, key) {
+	case "1", "true", "True", "t", "yes":
+		return true
+	}
+
+	return false
+}
+
+// List the LDAP authentication strategies
+func (c Connection) LDAPStrategies() ([]LDAPStrategy, error) {
+	return c.LDAPStrategiesContext(context.Background())
+}
+
+func (c Connection) LDAPStrategiesContext(ctx context.Context) ([]LDAPStrategy, error) {
+	return c.getLDAPStrategies(ctx, "/services/authentication/providers/LDAP")
+}
+
+// Get the LDAP authentication strategy name
+func (c Connection) LDAPStrategy(name string) (LDAPStrategy, error) {
+	return c.LDAPStrategyContext(context.Background(), name)
+}
+
+func (c Connection) LDAPStrategyContext(ctx context.Context, name string) (LDAPStrategy, error) {
+	strategies, err := c.getLDAPStrategies(ctx, fmt.Sprintf("/services/authentication/providers/LDAP/%s", url.PathEscape(name)))
+	if err != nil {
+		return LDAPStrategy{}, err
+	}
+	if len(strategies) == 0 {
+		return LDAPStrategy{}, fmt.Errorf("no LDAP strategy %s returned by splunk", name)
+	}
+
+	return strategies[0], nil
+}
+
+func (c Connection) getLDAPStrategies(ctx context.Context, endpoint string) ([]LDAPStrategy, error) {
+	data := make(url.Values)
+	data.Add("output_mode", "json")
+	data.Add("count", "0")
+
+	resp, respCode, err := c.httpCall(ctx, "GET", fmt.Sprintf("%s?%s", endpoint, data.Encode()), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return []LDAPStrategy{}, fmt.Errorf("unable to get LDAP strategies %w %d %s", err, respCode, string(resp))
+	}
+
+	respStruct := struct {
+		Entry []struct {
+			Name    string                 `json:"name"`
+			Content map[string]interface{} `json:"content"`
+		} `json:"entry"`
+	}{}
+	if err = json.Unmarshal(resp, &respStruct); err != nil {
+		return []LDAPStrategy{}, fmt.Errorf("unable to parse LDAP strategies from splunk: %s | response: %s", err, string(resp))
+	}
+
+	strategies := make([]LDAPStrategy, 0, len(respStruct.Entry))
+	for _, e := range respStruct.Entry {
+		strategies = append(strategies, ldapStrategyFromContent(e.Name, e.Content))
+	}
+
+	return strategies, nil
+}
+
+// Create an LDAP authentication strategy, Host, BindDN, UserBaseDN,
+// UserNameAttribute, RealNameAttribute and GroupBaseDN are required by splunk
+func (c Connection) LDAPStrategyCreate(strategy LDAPStrategy) error {
+	return c.LDAPStrategyCreateContext(context.Background(), strategy)
+}
+
+func (c Connection) LDAPStrategyCreateContext(ctx context.Context, strategy LDAPStrategy) error {
+	data := strategy.values()
+	data.Add("name", strategy.Name)
+
+	headers := map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	}
+
+	resp, respCode, err := c.httpCall(ctx, "POST", "/services/authentication/providers/LDAP", headers, []byte(data.Encode()))
+	if err != nil || (respCode != http.StatusOK && respCode != http.StatusCreated) {
+		return fmt.Errorf("unable to create LDAP strategy %s %w %d %s", strategy.Name, err, respCode, string(resp))
+	}
+
+	return nil
+}
+
+// Update the LDAP authentication strategy strategy.Name, empty string fields
+// (and a zero Port) are left unchanged, booleans are always set
+func (c Connection) LDAPStrategyUpdate(strategy LDAPStrategy) error {
+	return c.LDAPStrategyUpdateContext(context.Background(), strategy)
+}
+
+func (c Connection) LDAPStrategyUpdateContext(ctx context.Context, strategy LDAPStrategy) error {
+	data := strategy.values()
+
+	headers := map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	}
+
+	resp, respCode, err := c.httpCall(ctx, "POST", fmt.Sprintf("/services/authentication/providers/LDAP/%s", url.PathEscape(strategy.Name)), headers, []byte(data.Encode()))
+	if err != nil || respCode != http.StatusOK {
+		return fmt.Errorf("unable to update LDAP strategy %s %w %d %s", strategy.Name, err, respCode, string(resp))
+	}
+
+	return nil
+}
+
+// Delete the LDAP authentication strategy name
+func (c Connection) LDAPStrategyDelete(name string) error {
+	return c.LDAPStrategyDeleteContext(context.Background(), name)
+}
+
+func (c Connection) LDAPStrategyDeleteContext(ctx context.Context, name string) error {
+	resp, respCode, err := c.httpCall(ctx, "DELETE", fmt.Sprintf("/services/authentication/providers/LDAP/%s", url.PathEscape(name)), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return fmt.Errorf("unable to delete LDAP strategy %s %w %d %s", name, err, respCode, string(resp))
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// semaphores limiting the searches running on a Connection, keyed by searchSlotKey.
+// shared by copies of a Connection, as Connection is passed by value
+var searchSlots sync.Map
+
+func (c Connection) searchSlotKey() string {
+	return fmt.Sprintf("%s|%s|%d", c.Host, c.Username, c.MaxConcurrentSearches)
+}
+
+// wait for one of the Connection's MaxConcurrentSearches slots to be available,
+// the returned func releases the slot. no limit is applied when MaxConcurrentSearches is 0
+func (c Connection) acquireSearchSlot(ctx context.Context) (func(), error) {
+	if c.MaxConcurrentSearches <= 0 {
+		return func() {}, nil
+	}
+
+	slots, _ := searchSlots.LoadOrStore(c.searchSlotKey(), make(chan struct{}, c.MaxConcurrentSearches))
+	sem := slots.(chan struct{})
+
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-sem })
+	}, nil
+}
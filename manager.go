@@ -0,0 +1,454 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	log "log/slog"
+)
+
+const JOB_MANAGER_WORKERS = 4
+const JOB_MANAGER_QUEUE_SIZE = 100
+const JOB_MANAGER_RETAIN = 10 * time.Minute
+
+var ErrJobQueueFull = errors.New("job queue full")
+var ErrJobManagerClosed = errors.New("job manager closed")
+
+type JobPriority string
+
+// jobs are dispatched in this order, a job of a lower class only runs
+// when no job of a higher class is waiting (or its class is at its cap)
+const InteractivePriority JobPriority = "interactive"
+const BatchPriority JobPriority = "batch" // default for Submit
+const BackfillPriority JobPriority = "backfill"
+
+var jobPriorities = []JobPriority{InteractivePriority, BatchPriority, BackfillPriority}
+
+type JobState string
+
+const JobQueued JobState = "queued"
+const JobRunning JobState = "running"
+const JobDone JobState = "done"
+const JobFailed JobState = "failed"
+const JobCancelled JobState = "cancelled"
+
+// state of a search submitted to a JobManager
+type ManagedJob struct {
+	ID       string // assigned by the JobManager
+	Query    string
+	Priority JobPriority
+	Sid      string // set once the job is dispatched, for a partitioned search the sid of the job before it was partitioned
+	State    JobState
+	Err      error
+
+	Result SearchResult // set once the job is done
+
+	SubmittedAt time.Time
+	StartedAt   time.Time
+	FinishedAt  time.Time
+}
+
+type JobManagerOptions struct {
+	Workers   int // searches running at once, defaults to JOB_MANAGER_WORKERS
+	QueueSize int // searches waiting for a worker, defaults to JOB_MANAGER_QUEUE_SIZE
+
+	// max searches of a priority class running at once, a class without a cap
+	// can use all Workers. e.g. capping BackfillPriority keeps workers free for interactive searches
+	PriorityConcurrency map[JobPriority]int
+
+	// finished jobs (and their splunk search jobs) are kept for RetainFor,
+	// defaults to JOB_MANAGER_RETAIN, and reaped every ReapInterval (defaults to RetainFor / 2)
+	RetainFor    time.Duration
+	ReapInterval time.Duration
+}
+
+// queue of searches run by a pool of workers on a Connection.
+// jobs are tracked by ID until reaped, see JobManagerOptions.RetainFor
+type JobManager struct {
+	conn    Connection
+	options JobManagerOptions
+
+	mu      sync.Mutex
+	cond    *sync.Cond // signalled when a job is queued or finishes, and on Close
+	jobs    map[string]*managedJob
+	pending map[JobPriority][]string // queued job IDs, by class
+	queued  int
+	running map[JobPriority]int
+	nextID  int
+	closed  bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type managedJob struct {
+	ManagedJob
+
+	searchOptions SearchOptions
+	cancel        context.CancelFunc
+	done          chan struct{}
+	sids          []string // every job dispatched for it, including partitions, deleted when reaped
+}
+
+// Start a JobManager running searches on the Connection, Close it to stop its workers
+func (c Connection) NewJobManager(options JobManagerOptions) *JobManager {
+	if options.Workers <= 0 {
+		options.Workers = JOB_MANAGER_WORKERS
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = JOB_MANAGER_QUEUE_SIZE
+	}
+	if options.RetainFor <= 0 {
+		options.RetainFor = JOB_MANAGER_RETAIN
+	}
+	if options.ReapInterval <= 0 {
+		options.ReapInterval = options.RetainFor / 2
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &JobManager{
+		conn:    c,
+		options: options,
+		jobs:    make(map[string]*managedJob),
+		pending: make(map[JobPriority][]string),
+		running: make(map[JobPriority]int),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	m.cond = sync.NewCond(&m.mu)
+
+	for i := 0; i < options.Workers; i++ {
+		m.wg.Add(1)
+		go m.worker()
+	}
+	m.wg.Add(1)
+	go m.reaper()
+
+	return m
+}
+
+// Queue a search with BatchPriority, returning its ID.
+// fails with ErrJobQueueFull when QueueSize searches are already waiting
+func (m *JobManager) Submit(searchQuery string, searchOptions SearchOptions) (string, error) {
+	return m.SubmitPriority(searchQuery, searchOptions, BatchPriority)
+}
+
+func (m *JobManager) SubmitPriority(searchQuery string, searchOptions SearchOptions, priority JobPriority) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return "", ErrJobManagerClosed
+	}
+	if !validJobPriority(priority) {
+		return "", fmt.Errorf("unknown job priority %q", priority)
+	}
+	if m.queued >= m.options.QueueSize {
+		return "", ErrJobQueueFull
+	}
+
+	m.nextID++
+	id := fmt.Sprintf("job-%d", m.nextID)
+
+	job := &managedJob{
+		ManagedJob: ManagedJob{
+			ID:          id,
+			Query:       searchQuery,
+			Priority:    priority,
+			State:       JobQueued,
+			SubmittedAt: time.Now(),
+		},
+		searchOptions: searchOptions,
+		cancel:        func() {}, // set by the worker running the job
+		done:          make(chan struct{}),
+	}
+	m.jobs[id] = job
+	m.pending[priority] = append(m.pending[priority], id)
+	m.queued++
+	m.cond.Signal()
+
+	return id, nil
+}
+
+// Get the state of a job, false if there is no such job (or it was reaped)
+func (m *JobManager) Status(id string) (ManagedJob, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return ManagedJob{}, false
+	}
+
+	return job.ManagedJob, true
+}
+
+// Get the state of all tracked jobs, in submission order
+func (m *JobManager) Jobs() []ManagedJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]ManagedJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job.ManagedJob)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].SubmittedAt.Before(jobs[j].SubmittedAt)
+	})
+
+	return jobs
+}
+
+// Block until a job is finished (done, failed or cancelled) or ctx is done
+func (m *JobManager) Wait(ctx context.Context, id string) (ManagedJob, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		return ManagedJob{}, fmt.Errorf("unknown job %s", id)
+	}
+
+	select {
+	case <-job.done:
+	case <-ctx.Done():
+		return ManagedJob{}, ctx.Err()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return job.ManagedJob, job.Err
+}
+
+// Cancel a queued or running job, the splunk search job is cancelled as well
+func (m *JobManager) Cancel(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return fmt.Errorf("unknown job %s", id)
+	}
+	job.cancel()
+	if job.State == JobQueued {
+		m.dequeue(job)
+		m.finish(job, JobCancelled, context.Canceled)
+	}
+
+	return nil
+}
+
+// drop a queued job from its class's queue, so it no longer counts toward QueueSize.
+// m.mu must be held
+func (m *JobManager) dequeue(job *managedJob) {
+	pending := m.pending[job.Priority]
+	for i, id := range pending {
+		if id == job.ID {
+			m.pending[job.Priority] = append(pending[:i:i], pending[i+1:]...)
+			m.queued--
+			return
+		}
+	}
+}
+
+// Stop accepting jobs, cancel the queued and running ones, and wait for the workers to exit.
+// finished jobs can still be looked up, their splunk search jobs are left to expire
+func (m *JobManager) Close() {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	m.mu.Unlock()
+
+	m.cancel()
+	m.mu.Lock()
+	m.cond.Broadcast()
+	m.mu.Unlock()
+	m.wg.Wait()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, job := range m.jobs {
+		if job.State == JobQueued {
+			m.finish(job, JobCancelled, context.Canceled)
+		}
+	}
+}
+
+// mark a job as finished, m.mu must be held
+func (m *JobManager) finish(job *managedJob, state JobState, err error) {
+	select {
+	case <-job.done:
+		return
+	default:
+	}
+
+	job.State = state
+	job.Err = err
+	job.FinishedAt = time.Now()
+	close(job.done)
+}
+
+func validJobPriority(priority JobPriority) bool {
+	for _, p := range jobPriorities {
+		if p == priority {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pop the next job to run, highest priority class first, skipping classes at their cap
+// and jobs cancelled while queued. m.mu must be held
+func (m *JobManager) next() (*managedJob, bool) {
+	for _, priority := range jobPriorities {
+		if limit := m.options.PriorityConcurrency[priority]; limit > 0 && m.running[priority] >= limit {
+			continue
+		}
+
+		for len(m.pending[priority]) > 0 {
+			id := m.pending[priority][0]
+			m.pending[priority] = m.pending[priority][1:]
+			m.queued--
+
+			if job, ok := m.jobs[id]; ok && job.State == JobQueued {
+				return job, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (m *JobManager) worker() {
+	defer m.wg.Done()
+
+	for {
+		m.mu.Lock()
+		job, ok := m.next()
+		for !ok && m.ctx.Err() == nil {
+			m.cond.Wait()
+			job, ok = m.next()
+		}
+		if m.ctx.Err() != nil {
+			if ok {
+				m.finish(job, JobCancelled, context.Canceled)
+			}
+			m.mu.Unlock()
+			return
+		}
+
+		ctx, cancel := context.WithCancel(m.ctx)
+		job.cancel = cancel
+		job.State = JobRunning
+		job.StartedAt = time.Now()
+		m.running[job.Priority]++
+		m.mu.Unlock()
+
+		m.run(ctx, job)
+		cancel()
+
+		m.mu.Lock()
+		m.running[job.Priority]--
+		// a class may have dropped below its cap
+		m.cond.Broadcast()
+		m.mu.Unlock()
+	}
+}
+
+func (m *JobManager) run(ctx context.Context, job *managedJob) {
+	var result SearchResult
+	var err error
+	func() {
+		// a panic in the job (e.g. in its callbacks) should fail the job,
+		// not take down the whole process
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("job %s panicked: %v\n%s", job.ID, r, debug.Stack())
+			}
+		}()
+		result, err = m.search(ctx, job, job.searchOptions)
+	}()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case err == nil:
+		job.Result = result
+		m.finish(job, JobDone, nil)
+	case ctx.Err() != nil:
+		m.finish(job, JobCancelled, err)
+	default:
+		m.finish(job, JobFailed, err)
+	}
+}
+
+// run the job's search as the Search function would (partitioning it when allowed),
+// recording the sid of its first job status, and the sids of all its jobs to delete when reaped
+func (m *JobManager) search(ctx context.Context, job *managedJob, searchOptions SearchOptions) (SearchResult, error) {
+	onProgress := searchOptions.OnProgress
+	searchOptions.OnProgress = func(jobStatus SearchJobStatus) {
+		if sid := jobStatus.Content().Sid; sid != "" {
+			m.mu.Lock()
+			if job.Sid == "" {
+				job.Sid = sid
+			}
+			if !slices.Contains(job.sids, sid) {
+				job.sids = append(job.sids, sid)
+			}
+			m.mu.Unlock()
+		}
+
+		if onProgress != nil {
+			onProgress(jobStatus)
+		}
+	}
+
+	return m.conn.search(ctx, job.Query, searchOptions, 0)
+}
+
+// drop jobs finished more than RetainFor ago, deleting their splunk search jobs
+func (m *JobManager) reaper() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.options.ReapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		reaped := make(map[string][]string) // job ID to sids
+		m.mu.Lock()
+		for id, job := range m.jobs {
+			if job.FinishedAt.IsZero() || time.Since(job.FinishedAt) < m.options.RetainFor {
+				continue
+			}
+			reaped[id] = job.sids
+			delete(m.jobs, id)
+		}
+		m.mu.Unlock()
+
+		for id, sids := range reaped {
+			for _, sid := range sids {
+				if err := m.conn.SearchJobDelete(sid); err != nil {
+					// the job may have already expired on splunk
+					log.Debug("unable to delete reaped job", "id", id, "sid", sid, "err", err)
+				}
+			}
+		}
+	}
+}
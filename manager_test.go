@@ -0,0 +1,81 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJobManagerPanic(t *testing.T) {
+	c := newTestConnection(t, searchHandler(t, "test", 1))
+	m := c.NewJobManager(JobManagerOptions{Workers: 1})
+	defer m.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	id, err := m.Submit("search index=main", SearchOptions{
+		JobID: "test",
+		OnProgress: func(SearchJobStatus) {
+			panic("boom")
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, err := m.Wait(ctx, id)
+	if job.State != JobFailed || err == nil || !strings.Contains(err.Error(), "panicked: boom") {
+		t.Fatalf("got state %s and error %v, want the panic as the job's error", job.State, err)
+	}
+
+	// the worker survived the panic
+	id, err = m.Submit("search index=main", SearchOptions{JobID: "next"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job, err := m.Wait(ctx, id); err != nil || job.State != JobDone {
+		t.Fatalf("got state %s and error %v, want the next job done", job.State, err)
+	}
+}
+
+func TestJobManagerCancelQueued(t *testing.T) {
+	c := newTestConnection(t, searchHandler(t, "test", 1))
+	m := c.NewJobManager(JobManagerOptions{Workers: 1, QueueSize: 1})
+	defer m.Close()
+
+	// keep the only worker busy
+	running := make(chan struct{})
+	block := make(chan struct{})
+	defer close(block)
+	_, err := m.Submit("search index=main", SearchOptions{
+		JobID: "test",
+		OnProgress: func(SearchJobStatus) {
+			select {
+			case running <- struct{}{}:
+			default:
+			}
+			<-block
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-running
+
+	id, err := m.Submit("search index=main", SearchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Submit("search index=main", SearchOptions{}); err != ErrJobQueueFull {
+		t.Fatalf("got %v, want ErrJobQueueFull", err)
+	}
+
+	// the cancelled job frees its place in the queue
+	if err := m.Cancel(id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Submit("search index=main", SearchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,104 @@
+package go_splunk_rest
+
+// merges the results of a partitioned search, given in partition order
+// (time order for time partitions), into the search's results
+type MergePolicy func(partitions [][]map[string]interface{}) []map[string]interface{}
+
+// concatenate the partitions' results in partition order
+func AppendMerge(partitions [][]map[string]interface{}) []map[string]interface{} {
+	total := 0
+	for _, p := range partitions {
+		total += len(p)
+	}
+
+	merged := make([]map[string]interface{}, 0, total)
+	for _, p := range partitions {
+		merged = append(merged, p...)
+	}
+
+	return merged
+}
+
+// concatenate the partitions' results and sort them by _time
+func SortMerge(order PartitionSort) MergePolicy {
+	return func(partitions [][]map[string]interface{}) []map[string]interface{} {
+		merged := AppendMerge(partitions)
+		sortResults(merged, order)
+		return merged
+	}
+}
+
+// concatenate the partitions' results, dropping any result with the same values for
+// keyFields (DEFAULT_DEDUP_FIELDS if empty) as an earlier one, in any partition
+func DedupMerge(keyFields ...string) MergePolicy {
+	return func(partitions [][]map[string]interface{}) []map[string]interface{} {
+		return dedupResults(AppendMerge(partitions), keyFields)
+	}
+}
+
+// concatenate the partitions' results, dropping results of a partition with the same values
+// for keyFields (DEFAULT_DEDUP_FIELDS if empty) as a result of an earlier partition,
+// e.g. events on a partition boundary. duplicates within a partition are kept
+func FirstPartitionWinsMerge(keyFields ...string) MergePolicy {
+	if len(keyFields) == 0 {
+		keyFields = DEFAULT_DEDUP_FIELDS
+	}
+
+	return func(partitions [][]map[string]interface{}) []map[string]interface{} {
+		seen := make(map[string]bool)
+		merged := make([]map[string]interface{}, 0)
+		for _, p := range partitions {
+			keys := make([]string, 0, len(p))
+			for _, res := range p {
+				key, ok := dedupKey(Result(res), keyFields)
+				if ok && seen[key] {
+					continue
+				}
+				if ok {
+					keys = append(keys, key)
+				}
+				merged = append(merged, res)
+			}
+			// only mark the keys once the partition is done
+			for _, key := range keys {
+				seen[key] = true
+			}
+		}
+
+		return merged
+	}
+}
+
+// apply policies in order, each to the results of the previous one
+// (as a single partition), the first one gets the partitions
+func ChainMerge(policies ...MergePolicy) MergePolicy {
+	return func(partitions [][]map[string]interface{}) []map[string]interface{} {
+		if len(policies) == 0 {
+			return AppendMerge(partitions)
+		}
+
+		merged := policies[0](partitions)
+		for _, policy := range policies[1:] {
+			merged = policy([][]map[string]interface{}{merged})
+		}
+
+		return merged
+	}
+}
+
+// MergePolicy if set, otherwise the policy given by DedupPartitions and PartitionSort
+func (o SearchOptions) mergePolicy() MergePolicy {
+	if o.MergePolicy != nil {
+		return o.MergePolicy
+	}
+
+	policies := []MergePolicy{AppendMerge}
+	if o.DedupPartitions {
+		policies = append(policies, DedupMerge(o.DedupFields...))
+	}
+	if o.PartitionSort != NoSort {
+		policies = append(policies, SortMerge(o.PartitionSort))
+	}
+
+	return ChainMerge(policies...)
+}
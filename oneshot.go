@@ -0,0 +1,41 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Run a search with exec_mode=oneshot, returning the results in a single request
+// without creating a job to poll. Meant for small ad-hoc searches,
+// AllowPartition and the poll related options are ignored
+func (c Connection) SearchOneshot(searchQuery string, searchOptions SearchOptions) ([]map[string]interface{}, error) {
+	return c.SearchOneshotContext(context.Background(), searchQuery, searchOptions)
+}
+
+func (c Connection) SearchOneshotContext(ctx context.Context, searchQuery string, searchOptions SearchOptions) ([]map[string]interface{}, error) {
+	data := searchOptions.values(searchQuery)
+	data.Set("exec_mode", "oneshot")
+
+	// oneshot searches return up to count results, not max_count
+	data.Set("count", data.Get("max_count"))
+
+	headers := map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	}
+
+	resp, respCode, err := c.httpCall(ctx, "POST", c.servicePath("/search/jobs"), headers, []byte(data.Encode()))
+	if err != nil || respCode != http.StatusOK {
+		return []map[string]interface{}{}, fmt.Errorf("unable to run oneshot search %w %d %s", err, respCode, string(resp))
+	}
+
+	respStruct := struct {
+		Results []map[string]interface{} `json:"results"`
+	}{}
+	if err = json.Unmarshal(resp, &respStruct); err != nil {
+		return []map[string]interface{}{}, fmt.Errorf("unable to parse results from splunk: %s | response: %s", err, string(resp))
+	}
+
+	return c.transformResults(respStruct.Results)
+}
@@ -0,0 +1,91 @@
+// Package parquetexport writes splunk search results as Parquet, so exports can be
+// landed directly into a lakehouse. It is a separate module so the parquet
+// dependency is only pulled in by users of this package.
+package parquetexport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+	splunk "github.com/pvik/go-splunk-rest"
+)
+
+// writes search results as parquet rows, with every column an optional string
+// (splunk returns all values as strings), multivalue fields are joined with a newline
+type Writer struct {
+	pw      *parquet.Writer
+	columns []string
+}
+
+// create a Writer writing the given columns to w, fields of the results
+// that are not in columns are dropped. Close must be called to write the parquet footer
+func NewWriter(w io.Writer, columns []string) *Writer {
+	group := parquet.Group{}
+	for _, col := range columns {
+		group[col] = parquet.Optional(parquet.String())
+	}
+
+	return &Writer{
+		pw:      parquet.NewWriter(w, parquet.NewSchema("splunk", group)),
+		columns: columns,
+	}
+}
+
+func (w *Writer) WriteResult(res map[string]interface{}) error {
+	row := make(map[string]interface{}, len(w.columns))
+	for _, col := range w.columns {
+		v, ok := res[col]
+		if !ok || v == nil {
+			row[col] = nil
+			continue
+		}
+		row[col] = stringValue(v)
+	}
+
+	if err := w.pw.Write(row); err != nil {
+		return fmt.Errorf("unable to write parquet row: %s", err)
+	}
+
+	return nil
+}
+
+// flush buffered rows to the underlying writer
+func (w *Writer) Flush() error {
+	return w.pw.Flush()
+}
+
+// flush buffered rows and write the parquet footer, the underlying writer is not closed
+func (w *Writer) Close() error {
+	return w.pw.Close()
+}
+
+// Run a search through the streaming export endpoint, writing the results to w
+// as parquet as they are received
+func ExportSearch(ctx context.Context, c splunk.Connection, searchQuery string, searchOptions splunk.SearchOptions, w io.Writer, columns []string) error {
+	pw := NewWriter(w, columns)
+
+	err := c.SearchExportContext(ctx, searchQuery, searchOptions, pw.WriteResult)
+	if err != nil {
+		return err
+	}
+
+	return pw.Close()
+}
+
+func stringValue(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case []interface{}:
+		values := make([]string, len(val))
+		for i, mv := range val {
+			values[i] = stringValue(mv)
+		}
+		return strings.Join(values, "\n")
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
@@ -0,0 +1,85 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// breakdown of a search query as parsed by splunk
+// more details can be found here:
+// https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTsearch#search.2Fparser
+type ParsedSearch struct {
+	RemoteSearch      string `json:"remoteSearch"`
+	RemoteTimeOrdered bool   `json:"remoteTimeOrdered"`
+	EventsSearch      string `json:"eventsSearch"`
+	EventsTimeOrdered bool   `json:"eventsTimeOrdered"`
+	EventsStreaming   bool   `json:"eventsStreaming"`
+	ReportsSearch     string `json:"reportsSearch"`
+
+	Commands []struct {
+		Command      string `json:"command"`
+		RawArgs      string `json:"rawargs"`
+		Pipeline     string `json:"pipeline"`
+		IsGenerating bool   `json:"isGenerating"`
+		StreamType   string `json:"streamType"`
+	} `json:"commands"`
+}
+
+// returned by ParseSearch when splunk rejects the search query
+type SearchParseError struct {
+	Query    string
+	Messages []SearchMessage
+}
+
+func (e *SearchParseError) Error() string {
+	msgs := make([]string, 0, len(e.Messages))
+	for _, m := range e.Messages {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", m.Type, m.Message))
+	}
+
+	return fmt.Sprintf("unable to parse search: %s", strings.Join(msgs, " | "))
+}
+
+// Validate a search query with the splunk search parser without dispatching it,
+// a *SearchParseError is returned if the query is invalid
+func (c Connection) ParseSearch(searchQuery string) (ParsedSearch, error) {
+	return c.ParseSearchContext(context.Background(), searchQuery)
+}
+
+func (c Connection) ParseSearchContext(ctx context.Context, searchQuery string) (ParsedSearch, error) {
+	data := make(url.Values)
+	data.Add("q", searchQuery)
+	data.Add("output_mode", "json")
+
+	resp, respCode, err := c.httpCall(ctx, "GET", c.servicePath(fmt.Sprintf("/search/parser?%s", data.Encode())), map[string]string{}, []byte{})
+	if err != nil {
+		return ParsedSearch{}, fmt.Errorf("unable to parse search %w", err)
+	}
+
+	if respCode == http.StatusBadRequest {
+		respStruct := struct {
+			Messages []SearchMessage `json:"messages"`
+		}{}
+		if err = json.Unmarshal(resp, &respStruct); err == nil && len(respStruct.Messages) > 0 {
+			return ParsedSearch{}, &SearchParseError{
+				Query:    searchQuery,
+				Messages: respStruct.Messages,
+			}
+		}
+	}
+
+	if respCode != http.StatusOK {
+		return ParsedSearch{}, fmt.Errorf("unable to parse search %d %s", respCode, string(resp))
+	}
+
+	var respStruct ParsedSearch
+	if err = json.Unmarshal(resp, &respStruct); err != nil {
+		return ParsedSearch{}, fmt.Errorf("unable to parse search parser response from splunk: %s | response: %s", err, string(resp))
+	}
+
+	return respStruct, nil
+}
@@ -0,0 +1,606 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "log/slog"
+)
+
+type PartitionStrategy string
+
+// split the time range into PartitionCount equal parts
+const EqualTimePartition PartitionStrategy = "equal-time"
+
+// estimate the number of results in the time range from the time span covered
+// by the max count results returned, and split it into enough equal parts
+// (up to MAX_PARTITION_COUNT) for each to fit in max count.
+// falls back to PartitionCount if the results have no _time
+const AdaptivePartition PartitionStrategy = "adaptive"
+
+// before running the search, count the results over the time range
+// (DENSITY_BUCKETS buckets) with a cheap "| bin _time | stats count" search,
+// and split it into partitions with about the same number of results each.
+// the search query has to return events with a _time for the count to be meaningful
+const DensityPartition PartitionStrategy = "density"
+
+// split the search by search peer rather than time, running it once per
+// peer that is Up with splunk_server=<peer> added to the base search. this doesn't
+// need time bounds, and parallelizes better than time for short ranges on large clusters.
+// partitions which still hit max count are split by time (binary split), if the search has time bounds.
+// the search query has to start with "search" (not a generating command)
+const ServerPartition PartitionStrategy = "splunk-server"
+
+// headroom left in each adaptive partition, as data is rarely evenly spread
+const ADAPTIVE_PARTITION_FILL = 0.7
+
+type PartitionSort string
+
+const NoSort PartitionSort = ""
+const TimeAscending PartitionSort = "time-asc"
+const TimeDescending PartitionSort = "time-desc"
+
+// returned by the Search function when a partitioned search can't be split further
+// (MaxPartitionDepth reached, or a single second range) and still hits max count
+type PartitionDensityError struct {
+	EarliestTime time.Time
+	LatestTime   time.Time
+	MaxCount     int
+	Depth        int
+}
+
+func (e *PartitionDensityError) Error() string {
+	return fmt.Sprintf("more than %d results between %s and %s at partition depth %d, cannot partition further",
+		e.MaxCount, e.EarliestTime.Format(TIME_FORMAT), e.LatestTime.Format(TIME_FORMAT), e.Depth)
+}
+
+func (o SearchOptions) maxPartitionDepth() int {
+	if o.MaxPartitionDepth > 0 {
+		return o.MaxPartitionDepth
+	}
+
+	return DEFAULT_MAX_PARTITION_DEPTH
+}
+
+// time range searched by a partition, restricted to a search peer with SplunkServer
+type partitionWindow struct {
+	EarliestTime time.Time
+	LatestTime   time.Time
+	SplunkServer string
+}
+
+// split earliest - latest into count consecutive windows, on second boundaries.
+// fewer windows are returned when the range is too short for count windows
+// of whole seconds, so that none of them is empty
+func partitionWindows(earliest, latest time.Time, count int) []partitionWindow {
+	seconds := math.Ceil(latest.Sub(earliest).Seconds())
+	d := math.Max(math.Ceil(seconds/float64(count)), 1)
+	count = int(math.Max(math.Ceil(seconds/d), 1))
+
+	windows := make([]partitionWindow, count)
+	start := earliest
+	for i := range windows {
+		end := start.Add(time.Duration(d) * time.Second)
+		if end.After(latest) || i == count-1 {
+			end = latest
+		}
+		windows[i] = partitionWindow{EarliestTime: start, LatestTime: end}
+		start = end
+	}
+
+	return windows
+}
+
+// a partition of a search which failed, after its retries
+type PartitionError struct {
+	EarliestTime time.Time
+	LatestTime   time.Time
+	Err          error
+}
+
+func (e *PartitionError) Error() string {
+	return fmt.Sprintf("partition %s - %s failed: %s",
+		e.EarliestTime.Format(TIME_FORMAT), e.LatestTime.Format(TIME_FORMAT), e.Err)
+}
+
+func (e *PartitionError) Unwrap() error {
+	return e.Err
+}
+
+// returned by the Search function with AllowPartialResults, along with the
+// results of the partitions which succeeded, when some partitions failed
+type PartialResultsError struct {
+	Partitions []*PartitionError
+}
+
+func (e *PartialResultsError) Error() string {
+	msgs := make([]string, 0, len(e.Partitions))
+	for _, p := range e.Partitions {
+		msgs = append(msgs, p.Error())
+	}
+
+	return fmt.Sprintf("%d partitions failed: %s", len(e.Partitions), strings.Join(msgs, "; "))
+}
+
+func (e *PartialResultsError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Partitions))
+	for _, p := range e.Partitions {
+		errs = append(errs, p)
+	}
+
+	return errs
+}
+
+// search each window and merge the results.
+// with AllowPartialResults, the merged results are returned along with a *PartialResultsError
+func (c Connection) searchPartitioned(ctx context.Context, searchQuery string, searchOptions SearchOptions, partitionLevel int, windows []partitionWindow) (SearchResult, error) {
+	if searchOptions.OnProgress != nil {
+		if searchOptions.partitionTracker == nil {
+			searchOptions.partitionTracker = newPartitionTracker(searchOptions.OnProgress)
+		}
+		searchOptions.partitionTracker.addPartitions(searchOptions.partitionID, windows)
+	}
+
+	partitionedResults, err := c.searchPartitions(ctx, searchQuery, searchOptions, partitionLevel, windows)
+	var partialErr *PartialResultsError
+	if err != nil && !errors.As(err, &partialErr) {
+		return SearchResult{}, err
+	}
+
+	merged, mergeErr := c.mergePartitions(partitionedResults, searchOptions)
+	if mergeErr != nil {
+		return merged, mergeErr
+	}
+
+	return merged, err
+}
+
+// partitions of a level run at once, MaxConcurrentSearches (or PARTITION_CONCURRENCY),
+// and one at a time past CONCURRENT_PARTITION_DEPTH, as each goroutine polls splunkd
+// and nested partitions would multiply them
+func (c Connection) partitionConcurrency(partitionLevel int) int {
+	if partitionLevel >= CONCURRENT_PARTITION_DEPTH {
+		return 1
+	}
+	if c.MaxConcurrentSearches > 0 {
+		return c.MaxConcurrentSearches
+	}
+
+	return PARTITION_CONCURRENCY
+}
+
+// run a search for each window concurrently (see partitionConcurrency), returning the results in window order.
+// the first partition to fail (after its retries) cancels the others, and its error is returned.
+// with AllowPartialResults, the other partitions carry on, and the failed ones
+// are returned as a *PartialResultsError along with all the results
+func (c Connection) searchPartitions(ctx context.Context, searchQuery string, searchOptions SearchOptions, partitionLevel int, windows []partitionWindow) ([]SearchResult, error) {
+	parentCtx := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// each goroutine only writes its own index
+	results := make([]SearchResult, len(windows))
+	errs := make([]error, len(windows))
+
+	sem := make(chan struct{}, c.partitionConcurrency(partitionLevel))
+	var wg sync.WaitGroup
+	for i, w := range windows {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			// cancelled before the partition started
+			errs[i] = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(idx int, w partitionWindow) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer func() {
+				// a panic in one partition should fail the partition,
+				// not take down the whole process
+				if r := recover(); r != nil {
+					errs[idx] = fmt.Errorf("partition %d panicked: %v\n%s", idx, r, debug.Stack())
+					if !searchOptions.AllowPartialResults {
+						cancel()
+					}
+				}
+			}()
+
+			log.Debug("partition",
+				"level", partitionLevel,
+				"i", idx,
+				"start", w.EarliestTime.Format(TIME_FORMAT),
+				"end", w.LatestTime.Format(TIME_FORMAT),
+			)
+
+			partitionSearchOptions := searchOptions
+			partitionSearchOptions.EarliestTime = w.EarliestTime
+			partitionSearchOptions.LatestTime = w.LatestTime
+			partitionSearchOptions.JobID = partitionJobID(searchOptions.JobID, idx)
+			partitionSearchOptions.partitionID = partitionID(searchOptions.partitionID, idx)
+
+			partitionQuery := searchQuery
+			if w.SplunkServer != "" {
+				partitionQuery = restrictSearch(searchQuery, fmt.Sprintf("splunk_server=%q", w.SplunkServer))
+			}
+
+			rec, err := c.searchPartition(ctx, partitionQuery, partitionSearchOptions, partitionLevel+1)
+			// a partially failed partition still has results
+			results[idx] = rec
+			if err != nil {
+				if searchOptions.partitionTracker != nil {
+					searchOptions.partitionTracker.failed(partitionSearchOptions.partitionID, err)
+				}
+				errs[idx] = err
+				if !searchOptions.AllowPartialResults {
+					cancel()
+				}
+			}
+		}(i, w)
+	}
+	wg.Wait()
+
+	if !searchOptions.AllowPartialResults {
+		// partitions cancelled because of another partition's failure report context.Canceled,
+		// return the failure that caused it
+		for _, err := range errs {
+			if err != nil && !errors.Is(err, context.Canceled) {
+				return nil, err
+			}
+		}
+		for _, err := range errs {
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return results, nil
+	}
+
+	if err := parentCtx.Err(); err != nil {
+		return nil, err
+	}
+
+	var failed []*PartitionError
+	for idx, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		var partialErr *PartialResultsError
+		if errors.As(err, &partialErr) {
+			failed = append(failed, partialErr.Partitions...)
+			continue
+		}
+		failed = append(failed, &PartitionError{
+			EarliestTime: windows[idx].EarliestTime,
+			LatestTime:   windows[idx].LatestTime,
+			Err:          err,
+		})
+	}
+	if len(failed) > 0 {
+		return results, &PartialResultsError{Partitions: failed}
+	}
+
+	return results, nil
+}
+
+// run a partition's search, retrying it up to PartitionRetries times
+func (c Connection) searchPartition(ctx context.Context, searchQuery string, searchOptions SearchOptions, partitionLevel int) (SearchResult, error) {
+	wait := searchOptions.PartitionRetryWait
+	if wait <= 0 {
+		wait = RETRY_WAIT * time.Second
+	}
+	jobID := searchOptions.JobID
+
+	for attempt := 0; ; attempt++ {
+		rec, err := c.search(ctx, searchQuery, searchOptions, partitionLevel)
+		if err == nil || attempt >= searchOptions.PartitionRetries || !retryablePartitionError(ctx, err) {
+			return rec, err
+		}
+
+		log.Warn("partition failed, retrying",
+			"start", searchOptions.EarliestTime.Format(TIME_FORMAT),
+			"end", searchOptions.LatestTime.Format(TIME_FORMAT),
+			"attempt", attempt+1,
+			"err", err)
+
+		select {
+		case <-ctx.Done():
+			return rec, err
+		case <-time.After(wait):
+		}
+		wait *= 2
+
+		// the failed job may still be around, don't collide with its sid
+		if jobID != "" {
+			searchOptions.JobID = fmt.Sprintf("%s_r%d", jobID, attempt+1)
+		}
+	}
+}
+
+// failures that would happen again on a retry of the partition are not retried
+func retryablePartitionError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ErrResponseTooLarge) {
+		return false
+	}
+
+	var densityErr *PartitionDensityError
+	var partialErr *PartialResultsError
+	return !errors.As(err, &densityErr) && !errors.As(err, &partialErr)
+}
+
+// one partition for each search peer that is Up
+func (c Connection) serverPartitionWindows(ctx context.Context, searchOptions SearchOptions) ([]partitionWindow, error) {
+	peers, err := c.DistributedPeersContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var windows []partitionWindow
+	for _, peer := range peers {
+		if peer.Status != "Up" || peer.PeerName == "" {
+			log.Warn("skipping search peer", "peer", peer.Name, "status", peer.Status)
+			continue
+		}
+		windows = append(windows, partitionWindow{
+			EarliestTime: searchOptions.EarliestTime,
+			LatestTime:   searchOptions.LatestTime,
+			SplunkServer: peer.PeerName,
+		})
+	}
+
+	return windows, nil
+}
+
+// add term to the base search of searchQuery, right after the leading search command
+func restrictSearch(searchQuery, term string) string {
+	trimmed := strings.TrimSpace(searchQuery)
+	if len(trimmed) >= 7 && strings.EqualFold(trimmed[:7], "search ") {
+		return fmt.Sprintf("search %s %s", term, trimmed[7:])
+	}
+
+	return fmt.Sprintf("%s %s", term, trimmed)
+}
+
+// merge the results of partitioned searches, in partition order
+func (c Connection) mergePartitions(partitionedResults []SearchResult, searchOptions SearchOptions) (SearchResult, error) {
+	merged := SearchResult{}
+	partitions := make([][]map[string]interface{}, 0, len(partitionedResults))
+	total := 0
+	for idx, res := range partitionedResults {
+		log.Debug("partition results", "idx", idx, "count", len(res.Results))
+		total += len(res.Results)
+		if err := c.checkMaxResults(total); err != nil {
+			merged.Results = AppendMerge(partitions)
+			return merged, err
+		}
+		partitions = append(partitions, res.Results)
+		merged.Fields = mergeFields(merged.Fields, res.Fields)
+		merged.Messages = mergeMessages(merged.Messages, res.Messages)
+		merged.ResultCount += res.ResultCount
+		if res.Truncated {
+			merged.Truncated = true
+			merged.TruncatedAt = res.TruncatedAt
+		}
+	}
+
+	merged.Results = searchOptions.mergePolicy()(partitions)
+
+	return merged, nil
+}
+
+// run a pre-flight count of searchQuery's results over DENSITY_BUCKETS time buckets,
+// and group consecutive buckets into windows holding up to ADAPTIVE_PARTITION_FILL of max count.
+// a single window is returned when all the results fit in one search
+func (c Connection) densityPartitionWindows(ctx context.Context, searchQuery string, searchOptions SearchOptions) ([]partitionWindow, error) {
+	total := searchOptions.LatestTime.Sub(searchOptions.EarliestTime)
+	span := time.Duration(math.Ceil(total.Seconds()/DENSITY_BUCKETS)) * time.Second
+	if span < time.Second {
+		span = time.Second
+	}
+
+	countOptions := SearchOptions{
+		MaxCount:        DENSITY_BUCKETS + 1,
+		UseEarliestTime: true,
+		EarliestTime:    searchOptions.EarliestTime,
+		UseLatestTime:   true,
+		LatestTime:      searchOptions.LatestTime,
+		PollInterval:    searchOptions.PollInterval,
+		MaxPollInterval: searchOptions.MaxPollInterval,
+		PollBackoff:     searchOptions.PollBackoff,
+
+		DispatchRetries:   searchOptions.DispatchRetries,
+		DispatchRetryWait: searchOptions.DispatchRetryWait,
+	}
+	if searchOptions.JobID != "" {
+		countOptions.JobID = searchOptions.JobID + "_count"
+	}
+
+	countQuery := fmt.Sprintf("%s | bin _time span=%ds | stats count by _time", searchQuery, int(span.Seconds()))
+
+	release, err := c.acquireSearchSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sid, err := c.dispatchSearchJob(ctx, countQuery, countOptions)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	err = c.waitSearchJob(ctx, sid, countOptions, true)
+	release()
+	if err != nil {
+		return nil, err
+	}
+
+	// raw results, ResultTransforms are meant for the search's results
+	counts, err := c.searchJobResults(ctx, sid, ResultsOptions{Count: DENSITY_BUCKETS + 1})
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]int, int(math.Ceil(total.Seconds()/span.Seconds())))
+	for _, res := range counts.Results {
+		t, err := Result(res).Time()
+		if err != nil {
+			continue
+		}
+		count, err := Result(res).GetFloat("count")
+		if err != nil {
+			continue
+		}
+		i := int(t.Sub(searchOptions.EarliestTime) / span)
+		if i >= 0 && i < len(buckets) {
+			buckets[i] += int(count)
+		}
+	}
+
+	// buckets over the target get a window of their own, and are split further if they hit max count
+	target := int(float64(searchOptions.MaxCount) * ADAPTIVE_PARTITION_FILL)
+	var windows []partitionWindow
+	start := searchOptions.EarliestTime
+	filled := 0
+	for i, count := range buckets {
+		if filled > 0 && filled+count > target {
+			end := searchOptions.EarliestTime.Add(time.Duration(i) * span)
+			windows = append(windows, partitionWindow{EarliestTime: start, LatestTime: end})
+			start = end
+			filled = 0
+		}
+		filled += count
+	}
+	windows = append(windows, partitionWindow{EarliestTime: start, LatestTime: searchOptions.LatestTime})
+
+	log.Debug("density partitions", "sid", sid, "buckets", len(buckets), "partitions", len(windows))
+
+	return windows, nil
+}
+
+// number of partitions to split a search that returned result (hitting max count) into
+func (o SearchOptions) partitionCount(result SearchResult) int {
+	partitionCount := o.PartitionCount
+	if partitionCount <= 1 {
+		partitionCount = PARTITION_COUNT
+	}
+
+	if o.PartitionStrategy != AdaptivePartition {
+		return partitionCount
+	}
+
+	// time span covered by the returned results
+	var earliest, latest time.Time
+	for _, res := range result.Results {
+		t, err := Result(res).Time()
+		if err != nil {
+			continue
+		}
+		if earliest.IsZero() || t.Before(earliest) {
+			earliest = t
+		}
+		if latest.IsZero() || t.After(latest) {
+			latest = t
+		}
+	}
+
+	covered := latest.Sub(earliest)
+	total := o.LatestTime.Sub(o.EarliestTime)
+	if covered <= 0 || total <= 0 {
+		return partitionCount
+	}
+
+	estimatedResults := float64(o.MaxCount) * total.Seconds() / covered.Seconds()
+	adaptiveCount := int(math.Ceil(estimatedResults / (float64(o.MaxCount) * ADAPTIVE_PARTITION_FILL)))
+
+	if adaptiveCount < 2 {
+		adaptiveCount = 2
+	}
+	if adaptiveCount > MAX_PARTITION_COUNT {
+		adaptiveCount = MAX_PARTITION_COUNT
+	}
+
+	return adaptiveCount
+}
+
+// fields identifying an event, used to dedup partitioned results by default
+var DEFAULT_DEDUP_FIELDS = []string{"_cd", "_bkt"}
+
+// drop results with the same values for keyFields (DEFAULT_DEDUP_FIELDS if empty),
+// keeping the first occurrence. results missing all key fields are always kept
+func dedupResults(results []map[string]interface{}, keyFields []string) []map[string]interface{} {
+	if len(keyFields) == 0 {
+		keyFields = DEFAULT_DEDUP_FIELDS
+	}
+
+	seen := make(map[string]bool, len(results))
+	deduped := make([]map[string]interface{}, 0, len(results))
+	for _, res := range results {
+		key, ok := dedupKey(Result(res), keyFields)
+		if ok {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+
+		deduped = append(deduped, res)
+	}
+
+	return deduped
+}
+
+func dedupKey(res Result, keyFields []string) (string, bool) {
+	found := false
+	values := make([]string, len(keyFields))
+	for i, f := range keyFields {
+		if _, ok := res[f]; ok {
+			found = true
+		}
+		values[i] = strings.Join(res.GetStrings(f), "\x1f")
+	}
+
+	return strings.Join(values, "\x00"), found
+}
+
+// sort results by _time in order, results without a parsable _time go last.
+// the sort is stable, so results with the same _time keep their order
+func sortResults(results []map[string]interface{}, order PartitionSort) {
+	if order != TimeAscending && order != TimeDescending {
+		return
+	}
+
+	type timedResult struct {
+		t   time.Time
+		ok  bool
+		res map[string]interface{}
+	}
+
+	timed := make([]timedResult, len(results))
+	for i, res := range results {
+		t, err := Result(res).Time()
+		timed[i] = timedResult{t: t, ok: err == nil, res: res}
+	}
+
+	sort.SliceStable(timed, func(i, j int) bool {
+		if timed[i].ok != timed[j].ok {
+			return timed[i].ok
+		}
+		if order == TimeDescending {
+			return timed[i].t.After(timed[j].t)
+		}
+		return timed[i].t.Before(timed[j].t)
+	})
+
+	for i := range timed {
+		results[i] = timed[i].res
+	}
+}
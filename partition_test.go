@@ -0,0 +1,176 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fake splunkd search endpoints, jobs are done as soon as they are dispatched.
+// the job dispatched with sid rootSid returns rootCount results, every other job one
+func searchHandler(t *testing.T, rootSid string, rootCount int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/services/search/jobs")
+
+		resultCount := func(sid string) int {
+			if sid == rootSid {
+				return rootCount
+			}
+			return 1
+		}
+
+		switch {
+		case r.Method == "POST" && path == "":
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("unable to parse dispatch form: %s", err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"sid": %q}`, r.Form.Get("id"))
+
+		case strings.HasSuffix(path, "/control"):
+			fmt.Fprint(w, `{}`)
+
+		case strings.HasSuffix(path, "/results"):
+			sid := strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/results")
+			results := make([]map[string]interface{}, resultCount(sid))
+			for i := range results {
+				results[i] = map[string]interface{}{"sid": sid, "n": fmt.Sprintf("%d", i)}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+
+		default:
+			sid := strings.TrimPrefix(path, "/")
+			fmt.Fprintf(w, `{"entry": [{"content": {"sid": %q, "isDone": true, "dispatchState": "DONE", "resultCount": %d}}]}`,
+				sid, resultCount(sid))
+		}
+	}
+}
+
+func TestSearchPartitionPanic(t *testing.T) {
+	var mu sync.Mutex
+	var cancelled []string
+	handler := searchHandler(t, "test", 2)
+	c := newTestConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/control") {
+			r.ParseForm()
+			if r.Form.Get("action") == "cancel" {
+				mu.Lock()
+				defer mu.Unlock()
+				cancelled = append(cancelled, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/services/search/jobs/"), "/control"))
+			}
+		}
+		handler(w, r)
+	})
+	// the panicking partition must release its slot, or the next search deadlocks
+	c.MaxConcurrentSearches = 1
+
+	latest := time.Now().Truncate(time.Second)
+	_, err := c.Search("search index=main", SearchOptions{
+		MaxCount:        2,
+		AllowPartition:  true,
+		PartitionCount:  2,
+		UseEarliestTime: true,
+		EarliestTime:    latest.Add(-time.Hour),
+		UseLatestTime:   true,
+		LatestTime:      latest,
+		JobID:           "test",
+		OnProgress: func(s SearchJobStatus) {
+			if s.Content().Sid == "test_p1" {
+				panic("boom")
+			}
+		},
+	})
+	if err == nil {
+		t.Fatal("expected the partition's panic as an error")
+	}
+	if !strings.Contains(err.Error(), "partition 1 panicked: boom") {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(cancelled) != 1 || cancelled[0] != "test_p1" {
+		t.Fatalf("got cancelled jobs %v, want the panicking partition's job cancelled", cancelled)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.SearchContext(ctx, "search index=main", SearchOptions{JobID: "next"}); err != nil {
+		t.Fatalf("search after the panic: %s", err)
+	}
+}
+
+func TestSearchChanPartitions(t *testing.T) {
+	c := newTestConnection(t, searchHandler(t, "test", 2))
+
+	latest := time.Now().Truncate(time.Second)
+	searchOptions := SearchOptions{
+		MaxCount:        2,
+		AllowPartition:  true,
+		PartitionCount:  3,
+		UseEarliestTime: true,
+		EarliestTime:    latest.Add(-time.Hour),
+		UseLatestTime:   true,
+		LatestTime:      latest,
+		JobID:           "test",
+	}
+
+	sids := map[string]bool{}
+	results, errs := c.SearchChan("search index=main", searchOptions)
+	for res := range results {
+		sids[res.GetString("sid")] = true
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if len(sids) != 3 || !sids["test_p0"] || !sids["test_p1"] || !sids["test_p2"] {
+		t.Fatalf("got results from %v, want one from each partition", sids)
+	}
+
+	c.MaxResults = 2
+	results, errs = c.SearchChan("search index=main", searchOptions)
+	for range results {
+	}
+	if err := <-errs; !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("got %v, want ErrResponseTooLarge past MaxResults", err)
+	}
+}
+
+func TestPartitionWindows(t *testing.T) {
+	earliest := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		span  time.Duration
+		count int
+		want  int
+	}{
+		{name: "even split", span: time.Hour, count: 5, want: 5},
+		{name: "more windows than whole seconds allow", span: 7 * time.Second, count: 5, want: 4},
+		{name: "more windows than seconds", span: 3 * time.Second, count: 5, want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			latest := earliest.Add(tt.span)
+			windows := partitionWindows(earliest, latest, tt.count)
+			if len(windows) != tt.want {
+				t.Fatalf("got %d windows, want %d", len(windows), tt.want)
+			}
+
+			start := earliest
+			for i, w := range windows {
+				if !w.EarliestTime.Equal(start) || !w.LatestTime.After(w.EarliestTime) {
+					t.Fatalf("window %d is %s - %s, want a non empty window from %s", i, w.EarliestTime, w.LatestTime, start)
+				}
+				start = w.LatestTime
+			}
+			if !start.Equal(latest) {
+				t.Fatalf("windows end at %s, want %s", start, latest)
+			}
+		})
+	}
+}
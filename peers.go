@@ -0,0 +1,55 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// a search peer (indexer) of the search head
+type DistributedPeer struct {
+	Name     string // host:port of the peer
+	PeerName string `json:"peerName"` // server name, as in splunk_server
+	Status   string `json:"status"`   // Up, Down, ...
+	Version  string `json:"version"`
+	GUID     string `json:"guid"`
+}
+
+// Get the distributed search peers of the search head
+// more details can be found here:
+// https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTsearch#search.2Fdistributed.2Fpeers
+func (c Connection) DistributedPeers() ([]DistributedPeer, error) {
+	return c.DistributedPeersContext(context.Background())
+}
+
+func (c Connection) DistributedPeersContext(ctx context.Context) ([]DistributedPeer, error) {
+	data := make(url.Values)
+	data.Add("output_mode", "json")
+	data.Add("count", "0")
+
+	resp, respCode, err := c.httpCall(ctx, "GET", c.servicePath(fmt.Sprintf("/search/distributed/peers?%s", data.Encode())), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return []DistributedPeer{}, fmt.Errorf("unable to get distributed peers %w %d %s", err, respCode, string(resp))
+	}
+
+	respStruct := struct {
+		Entry []struct {
+			Name    string          `json:"name"`
+			Content DistributedPeer `json:"content"`
+		} `json:"entry"`
+	}{}
+	if err = json.Unmarshal(resp, &respStruct); err != nil {
+		return []DistributedPeer{}, fmt.Errorf("unable to parse distributed peers from splunk: %s | response: %s", err, string(resp))
+	}
+
+	peers := make([]DistributedPeer, 0, len(respStruct.Entry))
+	for _, e := range respStruct.Entry {
+		peer := e.Content
+		peer.Name = e.Name
+		peers = append(peers, peer)
+	}
+
+	return peers, nil
+}
@@ -0,0 +1,157 @@
+package go_splunk_rest
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// combined progress of a partitioned search, see SearchJobStatus.Partitions
+type PartitionProgress struct {
+	// the partitions being searched, by time range then search peer.
+	// partitions split further are replaced by their sub-partitions
+	Partitions []PartitionStatus
+
+	DoneProgress   float64 // average doneProgress of the partitions, 0.0 to 1.0
+	ResultsFetched int     // results fetched by the partitions so far
+}
+
+type PartitionStatus struct {
+	// index of the partition, with the index of each sub-partition
+	// appended, e.g. "2.1" for the second half of partition 2 split again
+	ID string
+
+	EarliestTime time.Time
+	LatestTime   time.Time
+	SplunkServer string // with ServerPartition
+
+	Sid            string
+	DispatchState  string
+	DoneProgress   float64 // 0.0 to 1.0
+	ResultsFetched int
+	Err            error
+}
+
+// tracks the partitions of a search for OnProgress,
+// shared by all the partitions of the search
+type partitionTracker struct {
+	mu       sync.Mutex
+	onUpdate func(SearchJobStatus)
+	status   map[string]*PartitionStatus
+	last     map[string]SearchJobStatus // last job status of each partition
+	split    map[string]bool
+}
+
+func newPartitionTracker(onUpdate func(SearchJobStatus)) *partitionTracker {
+	return &partitionTracker{
+		onUpdate: onUpdate,
+		status:   make(map[string]*PartitionStatus),
+		last:     make(map[string]SearchJobStatus),
+		split:    make(map[string]bool),
+	}
+}
+
+func partitionID(parentID string, idx int) string {
+	if parentID == "" {
+		return fmt.Sprintf("%d", idx)
+	}
+
+	return fmt.Sprintf("%s.%d", parentID, idx)
+}
+
+// register the sub-partitions of parentID ("" for the search itself)
+func (t *partitionTracker) addPartitions(parentID string, windows []partitionWindow) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if parentID != "" {
+		t.split[parentID] = true
+	}
+	for idx, w := range windows {
+		id := partitionID(parentID, idx)
+		t.status[id] = &PartitionStatus{
+			ID:           id,
+			EarliestTime: w.EarliestTime,
+			LatestTime:   w.LatestTime,
+			SplunkServer: w.SplunkServer,
+		}
+	}
+}
+
+// OnProgress of partition id's job, updating the partition's status
+// before passing the job status on to the search's OnProgress
+func (t *partitionTracker) onProgress(id string) func(SearchJobStatus) {
+	return func(jobStatus SearchJobStatus) {
+		t.update(id, &jobStatus, func(s *PartitionStatus) {
+			content := jobStatus.Content()
+			s.Sid = content.Sid
+			s.DispatchState = content.DispatchState
+			s.DoneProgress = content.DoneProgress
+		})
+	}
+}
+
+func (t *partitionTracker) fetched(id string, count int) {
+	t.update(id, nil, func(s *PartitionStatus) {
+		s.DoneProgress = 1
+		s.ResultsFetched = count
+		s.Err = nil // failed before being retried
+	})
+}
+
+func (t *partitionTracker) failed(id string, err error) {
+	t.update(id, nil, func(s *PartitionStatus) {
+		s.Err = err
+	})
+}
+
+// update partition id's status and report it, with jobStatus
+// or (when nil) the last job status of the partition
+func (t *partitionTracker) update(id string, jobStatus *SearchJobStatus, f func(*PartitionStatus)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.status[id]
+	if !ok {
+		return
+	}
+	f(s)
+
+	if jobStatus != nil {
+		t.last[id] = *jobStatus
+	}
+	t.emit(id)
+}
+
+// call onUpdate with the last job status of partition id and the combined progress,
+// t.mu must be held (so calls to onUpdate are serialized)
+func (t *partitionTracker) emit(id string) {
+	progress := PartitionProgress{
+		Partitions: make([]PartitionStatus, 0, len(t.status)),
+	}
+	for pid, s := range t.status {
+		if t.split[pid] {
+			continue
+		}
+		progress.Partitions = append(progress.Partitions, *s)
+		progress.DoneProgress += s.DoneProgress
+		progress.ResultsFetched += s.ResultsFetched
+	}
+	if len(progress.Partitions) > 0 {
+		progress.DoneProgress /= float64(len(progress.Partitions))
+	}
+
+	sort.Slice(progress.Partitions, func(i, j int) bool {
+		a, b := progress.Partitions[i], progress.Partitions[j]
+		if !a.EarliestTime.Equal(b.EarliestTime) {
+			return a.EarliestTime.Before(b.EarliestTime)
+		}
+		return a.SplunkServer < b.SplunkServer
+	})
+
+	jobStatus := t.last[id]
+	jobStatus.PartitionID = id
+	jobStatus.Partitions = &progress
+	t.onUpdate(jobStatus)
+}
@@ -0,0 +1,47 @@
+package go_splunk_rest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// proxy requests go through, ProxyURL or from HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+func (c Connection) proxy() func(*http.Request) (*url.URL, error) {
+	if c.ProxyURL == "" {
+		return http.ProxyFromEnvironment
+	}
+
+	proxyURL, err := url.Parse(c.ProxyURL)
+	if err != nil || proxyURL.Host == "" {
+		return func(*http.Request) (*url.URL, error) {
+			return nil, fmt.Errorf("invalid proxy url: %s", c.ProxyURL)
+		}
+	}
+
+	if proxyURL.Scheme == "socks5" && proxyURL.User == nil && c.ProxyUsername != "" {
+		// SOCKS5 authenticates with the url's credentials rather than headers
+		proxyURL.User = url.UserPassword(c.ProxyUsername, c.ProxyPassword)
+	}
+
+	return http.ProxyURL(proxyURL)
+}
+
+// headers authenticating to the proxy, nil if there is no proxy authentication
+func (c Connection) proxyHeaders() http.Header {
+	if c.ProxyUsername == "" && len(c.ProxyHeaders) == 0 {
+		return nil
+	}
+
+	header := make(http.Header)
+	if c.ProxyUsername != "" {
+		header.Set("Proxy-Authorization", "Basic "+
+			base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", c.ProxyUsername, c.ProxyPassword))))
+	}
+	for h, v := range c.ProxyHeaders {
+		header.Set(h, v)
+	}
+
+	return header
+}
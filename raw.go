@@ -0,0 +1,24 @@
+package go_splunk_rest
+
+import (
+	"html"
+	"regexp"
+)
+
+var rawMarkupRegexp = regexp.MustCompile(`</?(?:sg|v)\b[^>]*>`)
+
+// _raw of the result (event), empty if the result has no _raw field
+func (r Result) Raw() string {
+	return r.GetString("_raw")
+}
+
+// Remove splunk segmentation markup (<v>, <sg> tags, as returned with segmentation
+// or in xml output) from a _raw value, unescaping the xml entities in it.
+// values without markup are returned unchanged
+func StripRawMarkup(raw string) string {
+	if !rawMarkupRegexp.MatchString(raw) {
+		return raw
+	}
+
+	return html.UnescapeString(rawMarkupRegexp.ReplaceAllString(raw, ""))
+}
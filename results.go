@@ -0,0 +1,321 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const RESULTS_PAGE_SIZE = 5000
+
+// options for retrieving records of a search job
+type ResultsOptions struct {
+	// max number of records to return, 0 uses the splunk default
+	Count int
+	// index of the first record to return
+	Offset int
+	// fields to return, all fields are returned when empty.
+	// sent as repeated f= parameters, so only these fields are transferred
+	FieldList []string
+}
+
+func (o ResultsOptions) values() url.Values {
+	data := make(url.Values)
+	data.Add("output_mode", "json")
+
+	if o.Count > 0 {
+		data.Add("count", fmt.Sprintf("%d", o.Count))
+	}
+	if o.Offset > 0 {
+		data.Add("offset", fmt.Sprintf("%d", o.Offset))
+	}
+	for _, f := range o.FieldList {
+		data.Add("f", f)
+	}
+
+	return data
+}
+
+// a single search result row
+type Result map[string]interface{}
+
+// results of a search job, along with the metadata splunk returned with them
+type SearchResult struct {
+	Results []map[string]interface{}
+
+	// fields of the results in column order, as listed by splunk,
+	// including fields that have no value in any of the results
+	Fields []string
+
+	// messages splunk returned with the results
+	// (e.g. "lookup file not found", "search auto-finalized")
+	Messages []SearchMessage
+
+	// total number of results of the job(s) as reported by splunk,
+	// 0 when the results were fetched without checking the job status
+	ResultCount int
+
+	// set when splunk reports that the results were truncated
+	// (by max_count, maxresultrows, subsearch maxout, ...), when fewer results
+	// than ResultCount were returned, or when Search returned MaxCount results
+	// without being able to partition the search
+	Truncated bool
+	// row count the results were truncated at,
+	// 0 if splunk did not mention one in its message
+	TruncatedAt int
+}
+
+// message splunk attaches to a response (INFO, WARN, ERROR, FATAL, ...)
+type SearchMessage struct {
+	Type    string `json:"type"`
+	Message string `json:"text"`
+}
+
+// splunk messages reporting truncated results, the first group (when matched) is the row count
+// they were truncated at. messages merely mentioning max_count or maxresultrows don't match
+var truncationRegexps = []*regexp.Regexp{
+	// [subsearch]: Subsearch produced 50000 results, truncating to maxout 10000.
+	regexp.MustCompile(`(?i)subsearch produced \d+ results, truncating to maxout (\d+)`),
+	// The search result count (60000) exceeds maximum (50000), using max. To override it, set maxresultrows in limits.conf.
+	regexp.MustCompile(`(?i)result count \(\d+\) exceeds maximum \((\d+)\)`),
+	// Results truncated at 10000 rows. / Events were truncated to 1000.
+	regexp.MustCompile(`(?i)\b(?:results|events|output)\s+(?:(?:were|was|have been|has been)\s+)?truncated(?:\s+(?:at|to)\s+(\d+))?`),
+}
+
+// look through messages returned by splunk for a results truncated signal
+func parseTruncation(messages []SearchMessage) (bool, int) {
+	truncated := false
+	truncatedAt := 0
+
+	for _, m := range messages {
+		for _, re := range truncationRegexps {
+			match := re.FindStringSubmatch(m.Message)
+			if match == nil {
+				continue
+			}
+
+			truncated = true
+			if n, err := strconv.Atoi(match[1]); err == nil {
+				truncatedAt = n
+			}
+			break
+		}
+	}
+
+	return truncated, truncatedAt
+}
+
+// Get one page of the results of a search job
+func (c Connection) SearchJobResultsPage(jobID string, resultsOptions ResultsOptions) ([]map[string]interface{}, error) {
+	return c.SearchJobResultsPageContext(context.Background(), jobID, resultsOptions)
+}
+
+func (c Connection) SearchJobResultsPageContext(ctx context.Context, jobID string, resultsOptions ResultsOptions) ([]map[string]interface{}, error) {
+	result, err := c.searchJobResults(ctx, jobID, resultsOptions)
+	if err != nil {
+		return []map[string]interface{}{}, err
+	}
+
+	return c.transformResults(result.Results)
+}
+
+// Get all results of a search job, paging through them in RESULTS_PAGE_SIZE
+// pages up to the job's resultCount
+func (c Connection) SearchJobResultsAll(jobID string) ([]map[string]interface{}, error) {
+	return c.SearchJobResultsAllContext(context.Background(), jobID)
+}
+
+func (c Connection) SearchJobResultsAllContext(ctx context.Context, jobID string) ([]map[string]interface{}, error) {
+	result, err := c.searchJobResultsAll(ctx, jobID)
+	if err != nil {
+		return []map[string]interface{}{}, err
+	}
+
+	return result.Results, nil
+}
+
+func (c Connection) searchJobResultsAll(ctx context.Context, jobID string) (SearchResult, error) {
+	jobStatus, err := c.SearchJobStatusContext(ctx, jobID)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	resultCount := jobStatus.Content().ResultCount
+	if err := c.checkMaxResults(resultCount); err != nil {
+		return SearchResult{}, err
+	}
+
+	all := SearchResult{
+		Results:     make([]map[string]interface{}, 0, resultCount),
+		ResultCount: resultCount,
+	}
+	for offset := 0; offset < resultCount; offset += RESULTS_PAGE_SIZE {
+		page, err := c.searchJobResults(ctx, jobID, ResultsOptions{
+			Count:  RESULTS_PAGE_SIZE,
+			Offset: offset,
+		})
+		if err != nil {
+			if cerr := c.checkPagingContinuity(ctx, jobID, jobStatus); cerr != nil {
+				return all, cerr
+			}
+			return all, err
+		}
+
+		all.Results = append(all.Results, page.Results...)
+		all.Fields = mergeFields(all.Fields, page.Fields)
+		all.Messages = mergeMessages(all.Messages, page.Messages)
+		if page.Truncated {
+			all.Truncated = true
+			all.TruncatedAt = page.TruncatedAt
+		}
+
+		if len(page.Results) < RESULTS_PAGE_SIZE {
+			// fewer results available than the job reported
+			break
+		}
+	}
+
+	if resultCount > RESULTS_PAGE_SIZE {
+		// results were fetched in several requests, make sure they all came from the same job state
+		if err := c.checkPagingContinuity(ctx, jobID, jobStatus); err != nil {
+			return all, err
+		}
+	}
+
+	if len(all.Results) < resultCount && !all.Truncated {
+		all.Truncated = true
+		all.TruncatedAt = len(all.Results)
+	}
+
+	all.Results, err = c.transformResults(all.Results)
+	return all, err
+}
+
+// value of field as a string, the first value for multivalue fields,
+// empty if the field is not in the result
+func (r Result) GetString(field string) string {
+	values := r.GetStrings(field)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// values of field, a single value for scalar fields,
+// nil if the field is not in the result
+func (r Result) GetStrings(field string) []string {
+	v, ok := r[field]
+	if !ok || v == nil {
+		return nil
+	}
+
+	switch val := v.(type) {
+	case []interface{}:
+		values := make([]string, 0, len(val))
+		for _, mv := range val {
+			values = append(values, fmt.Sprintf("%v", mv))
+		}
+		return values
+	case string:
+		return []string{val}
+	default:
+		return []string{fmt.Sprintf("%v", val)}
+	}
+}
+
+// value of field as a float64, the first value for multivalue fields
+func (r Result) GetFloat(field string) (float64, error) {
+	if _, ok := r[field]; !ok {
+		return 0, fmt.Errorf("field %s not in result", field)
+	}
+
+	f, err := strconv.ParseFloat(r.GetString(field), 64)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse field %s as float: %s", field, err)
+	}
+
+	return f, nil
+}
+
+// value of field as a time.Time (see ParseTime), the first value for multivalue fields
+func (r Result) GetTime(field string) (time.Time, error) {
+	return r.timeField(field)
+}
+
+// returned when the results of a job changed while they were being paged through
+// (job still running, finalized, expired or re-dispatched under the same sid),
+// so the pages fetched are not consistent with each other
+type PagingContinuityError struct {
+	Sid    string
+	Reason string
+}
+
+func (e *PagingContinuityError) Error() string {
+	return fmt.Sprintf("results of search job %s changed while paging: %s", e.Sid, e.Reason)
+}
+
+// compare the job's current status with the one paging started with
+func (c Connection) checkPagingContinuity(ctx context.Context, jobID string, before SearchJobStatus) error {
+	after, err := c.SearchJobStatusContext(ctx, jobID)
+	if err != nil {
+		return &PagingContinuityError{Sid: jobID, Reason: fmt.Sprintf("job no longer available (ttl expired?): %s", err)}
+	}
+
+	b, a := before.Content(), after.Content()
+	switch {
+	case before.Published() != after.Published():
+		return &PagingContinuityError{Sid: jobID, Reason: fmt.Sprintf("job re-dispatched at %s", after.Published())}
+	case b.IsFinalized != a.IsFinalized:
+		return &PagingContinuityError{Sid: jobID, Reason: "job was finalized"}
+	case b.ResultCount != a.ResultCount:
+		return &PagingContinuityError{Sid: jobID, Reason: fmt.Sprintf("result count changed from %d to %d", b.ResultCount, a.ResultCount)}
+	}
+
+	return nil
+}
+
+// error if count exceeds Connection.MaxResults
+func (c Connection) checkMaxResults(count int) error {
+	if c.MaxResults > 0 && count > c.MaxResults {
+		return fmt.Errorf("%w: %d results (max %d)", ErrResponseTooLarge, count, c.MaxResults)
+	}
+
+	return nil
+}
+
+// fields followed by the ones in other not already in fields
+func mergeFields(fields, other []string) []string {
+	seen := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		seen[f] = true
+	}
+
+	for _, f := range other {
+		if !seen[f] {
+			seen[f] = true
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
+
+// messages followed by the ones in other not already in messages
+func mergeMessages(messages, other []SearchMessage) []SearchMessage {
+	seen := make(map[SearchMessage]bool, len(messages))
+	for _, m := range messages {
+		seen[m] = true
+	}
+
+	for _, m := range other {
+		if !seen[m] {
+			seen[m] = true
+			messages = append(messages, m)
+		}
+	}
+
+	return messages
+}
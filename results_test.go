@@ -0,0 +1,31 @@
+package go_splunk_rest
+
+import (
+	"testing"
+)
+
+func TestParseTruncation(t *testing.T) {
+	tests := []struct {
+		message     string
+		truncated   bool
+		truncatedAt int
+	}{
+		{"[subsearch]: Subsearch produced 50000 results, truncating to maxout 10000.", true, 10000},
+		{"The search result count (60000) exceeds maximum (50000), using max. To override it, set maxresultrows in limits.conf.", true, 50000},
+		{"Results truncated at 10000 rows.", true, 10000},
+		{"Events were truncated.", true, 0},
+
+		// mentions the limits without the results being truncated
+		{"max_count is set to 10000 for this search.", false, 0},
+		{"Configuration initialization for /opt/splunk/etc took 20ms, maxresultrows=50000", false, 0},
+		{"Truncating the search log is disabled.", false, 0},
+	}
+
+	for _, tt := range tests {
+		truncated, truncatedAt := parseTruncation([]SearchMessage{{Type: "INFO", Message: tt.message}})
+		if truncated != tt.truncated || truncatedAt != tt.truncatedAt {
+			t.Errorf("parseTruncation(%q) = %t, %d, want %t, %d",
+				tt.message, truncated, truncatedAt, tt.truncated, tt.truncatedAt)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "log/slog"
+)
+
+const RESUMABLE_EXPORT_CHUNK = time.Hour
+
+// persists the progress of a ResumableExport, e.g. in a file or database.
+// Load returns the zero time when there is no checkpoint for key
+type CheckpointStore interface {
+	Load(key string) (time.Time, error)
+	Save(key string, indexTime time.Time) error
+}
+
+type ResumableExportOptions struct {
+	// identifies the export in the CheckpointStore
+	Key string
+
+	// index time range to export, in chunks of Chunk (defaults to RESUMABLE_EXPORT_CHUNK)
+	From  time.Time
+	To    time.Time
+	Chunk time.Duration
+
+	// applied to every chunk's search, the index time bounds are overridden.
+	// set the event time bounds wide enough to cover the events indexed in From - To
+	SearchOptions SearchOptions
+}
+
+// Export the results of searchQuery for the events indexed between From and To, a chunk
+// of index time at a time, through the streaming export endpoint. after each chunk is written
+// to sink (and the sink flushed), the end of the chunk is saved to store,
+// and an export started again with the same Key resumes after it.
+// a chunk interrupted by a failure is exported again in full, so sink may receive duplicates
+func (c Connection) ResumableExport(searchQuery string, exportOptions ResumableExportOptions, store CheckpointStore, sink ResultSink) error {
+	return c.ResumableExportContext(context.Background(), searchQuery, exportOptions, store, sink)
+}
+
+func (c Connection) ResumableExportContext(ctx context.Context, searchQuery string, exportOptions ResumableExportOptions, store CheckpointStore, sink ResultSink) error {
+	if exportOptions.Key == "" {
+		return fmt.Errorf("resumable export needs a checkpoint key")
+	}
+	if !exportOptions.From.Before(exportOptions.To) {
+		return fmt.Errorf("invalid export range %s - %s",
+			exportOptions.From.Format(TIME_FORMAT), exportOptions.To.Format(TIME_FORMAT))
+	}
+
+	chunk := exportOptions.Chunk
+	if chunk <= 0 {
+		chunk = RESUMABLE_EXPORT_CHUNK
+	}
+
+	checkpoint, err := store.Load(exportOptions.Key)
+	if err != nil {
+		return fmt.Errorf("unable to load checkpoint %s: %s", exportOptions.Key, err)
+	}
+
+	start := exportOptions.From
+	if checkpoint.After(start) {
+		log.Info("resuming export", "key", exportOptions.Key, "checkpoint", checkpoint.Format(TIME_FORMAT))
+		start = checkpoint
+	}
+
+	for start.Before(exportOptions.To) {
+		end := start.Add(chunk)
+		if end.After(exportOptions.To) {
+			end = exportOptions.To
+		}
+
+		searchOptions := exportOptions.SearchOptions
+		searchOptions.IndexEarliestTime = start
+		searchOptions.IndexLatestTime = end
+
+		log.Debug("export chunk",
+			"key", exportOptions.Key,
+			"start", start.Format(TIME_FORMAT),
+			"end", end.Format(TIME_FORMAT))
+
+		if err := c.SearchExportToSinkContext(ctx, searchQuery, searchOptions, sink); err != nil {
+			return fmt.Errorf("export of chunk %s - %s failed: %w",
+				start.Format(TIME_FORMAT), end.Format(TIME_FORMAT), err)
+		}
+
+		if err := store.Save(exportOptions.Key, end); err != nil {
+			return fmt.Errorf("unable to save checkpoint %s: %s", exportOptions.Key, err)
+		}
+
+		start = end
+	}
+
+	return nil
+}
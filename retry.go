@@ -0,0 +1,135 @@
+package go_splunk_rest
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const RETRY_MAX_WAIT = 30      // seconds, cap of the backoff between retries
+const RETRY_BUDGET_WINDOW = 60 // seconds the RetryBudget is counted over
+
+// wait before retry attempt (0 based) of a failed http call, exponential backoff
+// from RetryWait (defaults to RETRY_WAIT seconds) capped at RetryMaxWait (defaults to RETRY_MAX_WAIT seconds),
+// with jitter so clients failing together don't retry together
+func (c Connection) retryBackoff(attempt int) time.Duration {
+	wait := c.RetryWait
+	if wait <= 0 {
+		wait = RETRY_WAIT * time.Second
+	}
+	maxWait := c.retryMaxWait()
+
+	for i := 0; i < attempt && wait < maxWait; i++ {
+		wait *= 2
+	}
+	if wait > maxWait {
+		wait = maxWait
+	}
+
+	// equal jitter, wait somewhere between half and all of the backoff
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(wait-half)+1))
+}
+
+// retries made by the Connections sharing a retryBudgetKey in the current window
+type retryBudget struct {
+	mu          sync.Mutex
+	windowStart time.Time
+	retries     int
+}
+
+// retry budgets of the Connections, keyed by retryBudgetKey.
+// shared by copies of a Connection, as Connection is passed by value
+var retryBudgets sync.Map
+
+func (c Connection) retryBudgetKey() string {
+	return fmt.Sprintf("%s|%s", c.Host, c.Username)
+}
+
+// take a retry from the Connection's RetryBudget, false once it is spent.
+// the budget stops retries from piling onto splunkd while it is struggling
+func (c Connection) takeRetry() bool {
+	if c.RetryBudget <= 0 {
+		return true
+	}
+
+	b, _ := retryBudgets.LoadOrStore(c.retryBudgetKey(), &retryBudget{})
+	budget := b.(*retryBudget)
+
+	budget.mu.Lock()
+	defer budget.mu.Unlock()
+
+	if time.Since(budget.windowStart) > RETRY_BUDGET_WINDOW*time.Second {
+		budget.windowStart = time.Now()
+		budget.retries = 0
+	}
+	if budget.retries >= c.RetryBudget {
+		return false
+	}
+	budget.retries++
+
+	return true
+}
+
+// max backoff between retries, RetryMaxWait or RETRY_MAX_WAIT seconds
+func (c Connection) retryMaxWait() time.Duration {
+	if c.RetryMaxWait > 0 {
+		return c.RetryMaxWait
+	}
+
+	return RETRY_MAX_WAIT * time.Second
+}
+
+// a request splunkd throttled (429, or 503 with Retry-After),
+// passed to Connection.OnThrottle
+type Throttle struct {
+	Method     string
+	Endpoint   string
+	StatusCode int
+	RetryAfter time.Duration // asked for by the Retry-After header, 0 if there was none
+	Retrying   bool          // false when the request isn't retried (retries disabled, exhausted, out of budget, or not retryable)
+	Wait       time.Duration // before retrying
+	Attempt    int
+}
+
+// whether resp is a throttled response, and the wait its Retry-After header asks for
+func throttled(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return retryAfter(resp), true
+	case http.StatusServiceUnavailable:
+		// only with a Retry-After, a plain 503 is a failure for isRetryable to decide on
+		wait := retryAfter(resp)
+		return wait, wait > 0
+	}
+
+	return 0, false
+}
+
+// wait asked for by resp's Retry-After header, in seconds or as a date. 0 if there is none
+func retryAfter(resp *http.Response) time.Duration {
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return 0
+	}
+
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(header); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(header); err == nil {
+		wait = time.Until(date)
+	}
+
+	if wait < 0 {
+		return 0
+	}
+
+	return wait
+}
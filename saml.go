@@ -0,0 +1,150 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// SAML configuration kinds, under /services/admin/SAML-<kind>
+const SAMLGroupsConfig = "groups"
+const SAMLIdPMetadataConfig = "idp-metadata"
+const SAMLSPMetadataConfig = "sp-metadata"
+const SAMLUserRoleMapConfig = "user-role-map"
+
+// an entry of a SAML configuration endpoint, with its settings
+type SAMLEntry struct {
+	Name    string
+	Content map[string]interface{}
+}
+
+// a SAML group, and the splunk roles its members get
+type SAMLGroup struct {
+	Name  string
+	Roles []string
+}
+
+// Get the entries of the SAML configuration kind (e.g. SAMLGroupsConfig, SAMLSPMetadataConfig)
+// more details can be found here:
+// https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTaccess#admin.2FSAML-groups
+func (c Connection) SAMLConfig(kind string) ([]SAMLEntry, error) {
+	return c.SAMLConfigContext(context.Background(), kind)
+}
+
+func (c Connection) SAMLConfigContext(ctx context.Context, kind string) ([]SAMLEntry, error) {
+	data := make(url.Values)
+	data.Add("output_mode", "json")
+	data.Add("count", "0")
+
+	resp, respCode, err := c.httpCall(ctx, "GET", fmt.Sprintf("/services/admin/SAML-%s?%s", kind, data.Encode()), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return []SAMLEntry{}, fmt.Errorf("unable to get SAML %s %w %d %s", kind, err, respCode, string(resp))
+	}
+
+	respStruct := struct {
+		Entry []struct {
+			Name    string                 `json:"name"`
+			Content map[string]interface{} `json:"content"`
+		} `json:"entry"`
+	}{}
+	if err = json.Unmarshal(resp, &respStruct); err != nil {
+		return []SAMLEntry{}, fmt.Errorf("unable to parse SAML %s from splunk: %s | response: %s", kind, err, string(resp))
+	}
+
+	entries := make([]SAMLEntry, 0, len(respStruct.Entry))
+	for _, e := range respStruct.Entry {
+		entries = append(entries, SAMLEntry{Name: e.Name, Content: e.Content})
+	}
+
+	return entries, nil
+}
+
+// Update the entry name of the SAML configuration kind with settings (passed as is),
+// or create an entry when name is empty ("name" is then expected in settings)
+func (c Connection) SAMLConfigUpdate(kind, name string, settings url.Values) error {
+	return c.SAMLConfigUpdateContext(context.Background(), kind, name, settings)
+}
+
+func (c Connection) SAMLConfigUpdateContext(ctx context.Context, kind, name string, settings url.Values) error {
+	data := make(url.Values)
+	for k, v := range settings {
+		data[k] = v
+	}
+	data.Set("output_mode", "json")
+
+	endpoint := fmt.Sprintf("/services/admin/SAML-%s", kind)
+	if name != "" {
+		endpoint = fmt.Sprintf("%s/%s", endpoint, url.PathEscape(name))
+	}
+
+	headers := map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	}
+
+	resp, respCode, err := c.httpCall(ctx, "POST", endpoint, headers, []byte(data.Encode()))
+	if err != nil || (respCode != http.StatusOK && respCode != http.StatusCreated) {
+		return fmt.Errorf("unable to update SAML %s %s %w %d %s", kind, name, err, respCode, string(resp))
+	}
+
+	return nil
+}
+
+// Get the SAML groups and their role mappings
+func (c Connection) SAMLGroups() ([]SAMLGroup, error) {
+	return c.SAMLGroupsContext(context.Background())
+}
+
+func (c Connection) SAMLGroupsContext(ctx context.Context) ([]SAMLGroup, error) {
+	entries, err := c.SAMLConfigContext(ctx, SAMLGroupsConfig)
+	if err != nil {
+		return []SAMLGroup{}, err
+	}
+
+	groups := make([]SAMLGroup, 0, len(entries))
+	for _, e := range entries {
+		group := SAMLGroup{Name: e.Name}
+		switch roles := e.Content["roles"].(type) {
+		case []interface{}:
+			for _, r := range roles {
+				group.Roles = append(group.Roles, fmt.Sprintf("%v", r))
+			}
+		case string:
+			group.Roles = []string{roles}
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+// Map the SAML group to roles, creating the group mapping if it doesn't exist
+func (c Connection) SAMLGroupSet(group SAMLGroup) error {
+	return c.SAMLGroupSetContext(context.Background(), group)
+}
+
+func (c Connection) SAMLGroupSetContext(ctx context.Context, group SAMLGroup) error {
+	data := make(url.Values)
+	data.Add("name", group.Name)
+	for _, r := range group.Roles {
+		data.Add("roles", r)
+	}
+
+	// the groups endpoint creates or replaces the mapping of name
+	return c.SAMLConfigUpdateContext(ctx, SAMLGroupsConfig, "", data)
+}
+
+// Delete the role mapping of the SAML group
+func (c Connection) SAMLGroupDelete(name string) error {
+	return c.SAMLGroupDeleteContext(context.Background(), name)
+}
+
+func (c Connection) SAMLGroupDeleteContext(ctx context.Context, name string) error {
+	resp, respCode, err := c.httpCall(ctx, "DELETE", fmt.Sprintf("/services/admin/SAML-groups/%s", url.PathEscape(name)), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return fmt.Errorf("unable to delete SAML group %s %w %d %s", name, err, respCode, string(resp))
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Dispatch the saved search name, returning the sid of the created job,
+// which can be used with SearchJobStatus, SearchJobResults, etc.
+// dispatchArgs are passed as is, e.g. dispatch.earliest_time, args.<macro arg>, trigger_actions
+// more details can be found here:
+// https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTsearch#saved.2Fsearches.2F.7Bname.7D.2Fdispatch
+func (c Connection) SavedSearchDispatch(name string, dispatchArgs url.Values) (string, error) {
+	return c.SavedSearchDispatchContext(context.Background(), name, dispatchArgs)
+}
+
+func (c Connection) SavedSearchDispatchContext(ctx context.Context, name string, dispatchArgs url.Values) (string, error) {
+	data := make(url.Values)
+	for k, v := range dispatchArgs {
+		data[k] = v
+	}
+	data.Set("output_mode", "json")
+
+	headers := map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	}
+
+	resp, respCode, err := c.httpCall(ctx, "POST", c.servicePath(fmt.Sprintf("/saved/searches/%s/dispatch", url.PathEscape(name))), headers, []byte(data.Encode()))
+	if err != nil || respCode != http.StatusCreated {
+		return "", fmt.Errorf("unable to dispatch saved search %s %w %d %s", name, err, respCode, string(resp))
+	}
+
+	respStruct := struct {
+		Sid string `json:"sid"`
+	}{}
+	if err = json.Unmarshal(resp, &respStruct); err != nil {
+		return "", fmt.Errorf("unable to parse sid from splunk: %s | response: %s", err, string(resp))
+	}
+
+	return respStruct.Sid, nil
+}
+
+type DispatchAs string
+
+const DispatchAsOwner DispatchAs = "owner" // run with the saved search owner's roles (default)
+const DispatchAsUser DispatchAs = "user"   // run with the roles of the user dispatching it, see Connection.AsUser
+
+// Set whether the saved search name runs as its owner or as the user dispatching it
+func (c Connection) SavedSearchSetDispatchAs(name string, dispatchAs DispatchAs) error {
+	return c.SavedSearchSetDispatchAsContext(context.Background(), name, dispatchAs)
+}
+
+func (c Connection) SavedSearchSetDispatchAsContext(ctx context.Context, name string, dispatchAs DispatchAs) error {
+	data := make(url.Values)
+	data.Add("dispatchAs", string(dispatchAs))
+	data.Add("output_mode", "json")
+
+	headers := map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	}
+
+	resp, respCode, err := c.httpCall(ctx, "POST", c.servicePath(fmt.Sprintf("/saved/searches/%s", url.PathEscape(name))), headers, []byte(data.Encode()))
+	if err != nil || respCode != http.StatusOK {
+		return fmt.Errorf("unable to update saved search %s %w %d %s", name, err, respCode, string(resp))
+	}
+
+	return nil
+}
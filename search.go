@@ -1,11 +1,15 @@
 package go_splunk_rest
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"math"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,9 +18,26 @@ import (
 
 const DEFAULT_MAX_COUNT = 10000
 const SEARCH_WAIT = 5
+const SEARCH_WAIT_JITTER = 0.2 // max fraction of the poll interval added as random jitter
 const TIME_FORMAT = "01/02/2006:15:04:05"
 const SPLUNK_TIME_FORMAT = "%m/%d/%Y:%H:%M:%S"
 const PARTITION_COUNT = 5
+const MAX_PARTITION_COUNT = 50 // upper bound on the partitions AdaptivePartition splits a search into
+const DEFAULT_MAX_PARTITION_DEPTH = 8
+const DENSITY_BUCKETS = 100 // time buckets counted by the DensityPartition pre-flight search
+
+const PARTITION_CONCURRENCY = 5      // partitions of a search running at once, when MaxConcurrentSearches isn't set
+const CONCURRENT_PARTITION_DEPTH = 2 // partitions nested deeper run one at a time
+
+type PollBackoff string
+
+const ConstantBackoff PollBackoff = "constant"       // poll at PollInterval
+const ExponentialBackoff PollBackoff = "exponential" // double the interval after every poll, up to MaxPollInterval
+
+type ExecMode string
+
+const NormalExecMode ExecMode = "normal"     // return the sid as soon as the job is created
+const BlockingExecMode ExecMode = "blocking" // return the sid once splunk completes the job
 
 // hold options that can be passed to a search job
 // more details can be found here:
@@ -34,24 +55,221 @@ type SearchOptions struct {
 	UseLatestTime bool
 	LatestTime    time.Time
 
+	// bounds on the index time (_indextime) of the searched events, ignored when zero
+	IndexEarliestTime time.Time
+	IndexLatestTime   time.Time
+
 	// In the Search function ; for searches which hit the maxCount,
 	// to recursively create new searches on reduced time ranges
 	// (by using shrinking earliest and latest time fields)
 	// and combine the results at the end
 	AllowPartition bool
+
+	// In the Search function ; with AllowPartition, the number of partitions
+	// to split the time range into, defaults to PARTITION_COUNT
+	PartitionCount int
+	// In the Search function ; with AllowPartition, how the partitions are chosen,
+	// defaults to EqualTimePartition. this applies to the first split,
+	// partitions which still hit max count are split in two (binary split)
+	PartitionStrategy PartitionStrategy
+	// In the Search function ; with AllowPartition, how many times a partition
+	// can be split again, defaults to DEFAULT_MAX_PARTITION_DEPTH.
+	// a *PartitionDensityError is returned when a partition at this depth
+	// (or a single second) still hits max count
+	MaxPartitionDepth int
+
+	// In the Search function ; with AllowPartition, drop duplicate results
+	// (e.g. events on a partition boundary) when merging partitioned results.
+	// results are keyed on DedupFields, which defaults to _cd and _bkt
+	DedupPartitions bool
+	DedupFields     []string
+
+	// In the Search function ; with AllowPartition, sort the merged
+	// partitioned results by _time. defaults to NoSort, where partitions
+	// are merged in time range order without sorting the results
+	PartitionSort PartitionSort
+	// In the Search function ; with AllowPartition, how partitioned results are merged,
+	// overrides DedupPartitions and PartitionSort. see AppendMerge, SortMerge,
+	// DedupMerge, FirstPartitionWinsMerge and ChainMerge
+	MergePolicy MergePolicy
+
+	// In the Search function ; with AllowPartition, how many times a failed
+	// partition is retried, waiting PartitionRetryWait (defaults to RETRY_WAIT seconds)
+	// before the first retry, doubled for every following one
+	PartitionRetries   int
+	PartitionRetryWait time.Duration
+	// In the Search function ; with AllowPartition, return the results of the
+	// partitions which succeeded along with a *PartialResultsError listing the
+	// failed ones, instead of failing the whole search
+	AllowPartialResults bool
+
+	// In the Search function ; when set, the job's preview results are
+	// fetched on every poll while the job is running and passed to OnPreview.
+	// with AllowPartition, this is called for each partitioned search
+	OnPreview func([]map[string]interface{})
+	// with OnPreview, only pass the preview rows that are new since the last poll,
+	// for progressive rendering. meant for non transforming searches,
+	// whose previews only grow, rather than being recomputed
+	IncrementalPreview bool
+
+	// In the Search function ; called with the job status on every poll.
+	// with AllowPartition, once the search is partitioned this is called for each
+	// partition's job, with the status's PartitionID and Partitions (the combined
+	// progress of all the partitions) set. calls for partitions are serialized
+	OnProgress func(SearchJobStatus)
+
+	// In the Search function ; how many times dispatching the search job is retried
+	// when splunk refuses it for lack of concurrent search or disk quota, or throttles it (see DispatchError.IsQuota),
+	// waiting DispatchRetryWait (defaults to SEARCH_WAIT seconds) before the first retry,
+	// doubled for every following one. OnProgress is called with a QUEUED job status
+	// (no sid, the dispatch error as a message) before each wait
+	DispatchRetries   int
+	DispatchRetryWait time.Duration
+
+	// In the Search function ; interval between job status polls,
+	// defaults to SEARCH_WAIT seconds
+	PollInterval time.Duration
+	// upper bound of the poll interval with ExponentialBackoff,
+	// defaults to SEARCH_WAIT seconds
+	MaxPollInterval time.Duration
+	// how the poll interval changes between polls, defaults to ConstantBackoff
+	PollBackoff PollBackoff
+
+	// sets the auto_cancel dispatch parameter (whole seconds) so splunk cancels
+	// the job once it's inactive for this long, and in the Search function
+	// is the deadline for the whole search, after which the job is cancelled
+	// and a *SearchTimeoutError is returned
+	Timeout time.Duration
+
+	// exec_mode of the job, defaults to NormalExecMode.
+	// with BlockingExecMode, SearchJobCreate only returns once the job is done,
+	// so the job must complete within the http client timeout
+	ExecMode ExecMode
+
+	// dispatch tuning parameters, left to the splunk defaults when zero
+	StatusBuckets          int           // status_buckets, needed for summary and timeline
+	RequiredFields         []string      // rf, fields to extract even in fast mode
+	AdhocSearchLevel       string        // adhoc_search_level: verbose, fast or smart
+	AutoFinalizeEventCount int           // auto_finalize_ec, finalize the job after this many events
+	AutoPause              time.Duration // auto_pause, pause the job after being inactive this long
+	JobTTL                 time.Duration // timeout, how long to keep the job artifacts after last access
+	SampleRatio            int           // sample_ratio, return 1 in SampleRatio events
+	IndexedRealtime        bool          // indexedRealtime, use indexed realtime for realtime searches
+
+	// id, sid to give the dispatched job instead of a generated one,
+	// must be unique among the jobs on the search head.
+	// partitioned searches get "_p<partition>" appended for each partition
+	JobID string
+
+	// set on partitions' options, when tracking their progress for OnProgress
+	partitionTracker *partitionTracker
+	partitionID      string
+
+	// set by SearchChan, called with the results of the search (or of each partition)
+	// as it completes, which are then left out of the returned results
+	onResults func(SearchResult) error
+}
+
+// returned by the Search function when the search did not complete
+// within SearchOptions.Timeout (or the context deadline)
+type SearchTimeoutError struct {
+	Sid     string
+	Timeout time.Duration
+}
+
+func (e *SearchTimeoutError) Error() string {
+	return fmt.Sprintf("search job %s timed out after %s", e.Sid, e.Timeout)
+}
+
+// interval to wait before the first job status poll
+func (o SearchOptions) pollInterval() time.Duration {
+	if o.PollInterval > 0 {
+		return o.PollInterval
+	}
+
+	return SEARCH_WAIT * time.Second
+}
+
+// interval to wait before the next job status poll, given the previous interval
+func (o SearchOptions) nextPollInterval(prev time.Duration) time.Duration {
+	if o.PollBackoff != ExponentialBackoff {
+		return prev
+	}
+
+	maxInterval := o.MaxPollInterval
+	if maxInterval <= 0 {
+		maxInterval = SEARCH_WAIT * time.Second
+	}
+
+	next := prev * 2
+	if next > maxInterval {
+		next = maxInterval
+	}
+	if next < prev {
+		// PollInterval larger than MaxPollInterval, keep polling at PollInterval
+		next = prev
+	}
+
+	return next
 }
 
 type SearchJobStatus struct {
-	Messages []struct {
-		Type    string `json:"type"`
-		Message string `json:"text"`
+	Messages []SearchMessage
+	Entry    []SearchJobEntry `json:"entry"`
+
+	// set on the statuses of a partitioned search's partitions passed to OnProgress,
+	// the partition's ID (see PartitionStatus.ID) and the combined progress of all the partitions
+	PartitionID string             `json:"-"`
+	Partitions  *PartitionProgress `json:"-"`
+}
+
+type SearchJobEntry struct {
+	Published string           `json:"published"` // dispatch time of the job
+	Content   SearchJobContent `json:"content"`
+}
+
+type SearchJobContent struct {
+	Sid      string `json:"sid"`
+	IsDone   bool   `json:"isDone"`
+	IsFailed bool   `json:"isFailed"`
+
+	IsFinalized bool `json:"isFinalized"`
+
+	// QUEUED, PARSING, RUNNING, PAUSED, FINALIZING, FAILED, DONE
+	DispatchState string  `json:"dispatchState"`
+	DoneProgress  float64 `json:"doneProgress"` // 0.0 to 1.0
+	EventCount    int     `json:"eventCount"`
+	ResultCount   int     `json:"resultCount"`
+	ScanCount     int     `json:"scanCount"`
+	RunDuration   float64 `json:"runDuration"` // seconds
+	TTL           int     `json:"ttl"`         // seconds
+
+	// transforming part of the search, empty for event (non transforming) searches
+	ReportSearch string `json:"reportSearch"`
+}
+
+// whether the job's search is transforming (e.g. uses stats, chart, timechart),
+// so its output is read from the results endpoint rather than the events endpoint
+func (s SearchJobStatus) IsTransforming() bool {
+	return s.Content().ReportSearch != ""
+}
+
+// dispatch time of the job as reported by splunk, empty if splunk returned no entry
+func (s SearchJobStatus) Published() string {
+	if len(s.Entry) > 0 {
+		return s.Entry[0].Published
 	}
-	Entry []struct {
-		Content struct {
-			IsDone   bool `json:"isDone"`
-			IsFailed bool `json:"isFailed"`
-		} `json:"content"`
-	} `json:"entry"`
+
+	return ""
+}
+
+// content of the job entry, zero value if splunk returned no entry
+func (s SearchJobStatus) Content() SearchJobContent {
+	if len(s.Entry) > 0 {
+		return s.Entry[0].Content
+	}
+
+	return SearchJobContent{}
 }
 
 func (s SearchJobStatus) IsDone() (bool, error) {
@@ -72,33 +290,102 @@ func (s SearchJobStatus) IsDone() (bool, error) {
 	return false, nil
 }
 
-func (c Connection) SearchJobCreate(searchQuery string, searchOptions SearchOptions) (string, error) {
+// Dispatch a search job and return a handle on it, without waiting for it to complete
+func (c Connection) SearchJobCreate(searchQuery string, searchOptions SearchOptions) (*SearchJob, error) {
+	return c.SearchJobCreateContext(context.Background(), searchQuery, searchOptions)
+}
+
+func (c Connection) SearchJobCreateContext(ctx context.Context, searchQuery string, searchOptions SearchOptions) (*SearchJob, error) {
+	sid, err := c.createSearchJob(ctx, searchQuery, searchOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SearchJob{
+		Sid:           sid,
+		conn:          c,
+		searchOptions: searchOptions,
+	}, nil
+}
+
+// dispatch parameters for searchQuery based on the search options
+func (o SearchOptions) values(searchQuery string) url.Values {
 	data := make(url.Values)
 	data.Add("search", searchQuery)
 	data.Add("output_mode", "json")
 
-	if searchOptions.MaxCount == 0 {
-		searchOptions.MaxCount = DEFAULT_MAX_COUNT
+	if o.MaxCount == 0 {
+		o.MaxCount = DEFAULT_MAX_COUNT
 	}
 
-	data.Add("max_count", fmt.Sprintf("%d", searchOptions.MaxCount))
+	data.Add("max_count", fmt.Sprintf("%d", o.MaxCount))
 	data.Add("time_format", SPLUNK_TIME_FORMAT)
 
-	if searchOptions.UseEarliestTime {
-		data.Add("earliest_time", searchOptions.EarliestTime.Format(TIME_FORMAT))
+	if o.UseEarliestTime {
+		data.Add("earliest_time", o.EarliestTime.Format(TIME_FORMAT))
 	}
 
-	if searchOptions.UseLatestTime {
-		data.Add("latest_time", searchOptions.LatestTime.Format(TIME_FORMAT))
+	if o.UseLatestTime {
+		data.Add("latest_time", o.LatestTime.Format(TIME_FORMAT))
 	}
 
+	if !o.IndexEarliestTime.IsZero() {
+		data.Add("index_earliest", fmt.Sprintf("%d", o.IndexEarliestTime.Unix()))
+	}
+	if !o.IndexLatestTime.IsZero() {
+		data.Add("index_latest", fmt.Sprintf("%d", o.IndexLatestTime.Unix()))
+	}
+
+	if o.Timeout > 0 {
+		data.Add("auto_cancel", fmt.Sprintf("%d", int(o.Timeout.Seconds())))
+	}
+
+	if o.ExecMode != "" {
+		data.Add("exec_mode", string(o.ExecMode))
+	}
+
+	if o.StatusBuckets > 0 {
+		data.Add("status_buckets", fmt.Sprintf("%d", o.StatusBuckets))
+	}
+	for _, f := range o.RequiredFields {
+		data.Add("rf", f)
+	}
+	if o.AdhocSearchLevel != "" {
+		data.Add("adhoc_search_level", o.AdhocSearchLevel)
+	}
+	if o.AutoFinalizeEventCount > 0 {
+		data.Add("auto_finalize_ec", fmt.Sprintf("%d", o.AutoFinalizeEventCount))
+	}
+	if o.AutoPause > 0 {
+		data.Add("auto_pause", fmt.Sprintf("%d", int(o.AutoPause.Seconds())))
+	}
+	if o.JobTTL > 0 {
+		data.Add("timeout", fmt.Sprintf("%d", int(o.JobTTL.Seconds())))
+	}
+	if o.SampleRatio > 0 {
+		data.Add("sample_ratio", fmt.Sprintf("%d", o.SampleRatio))
+	}
+	if o.IndexedRealtime {
+		data.Add("indexedRealtime", "true")
+	}
+	if o.JobID != "" {
+		data.Add("id", o.JobID)
+	}
+
+	return data
+}
+
+// dispatch a search job, returning its sid
+func (c Connection) createSearchJob(ctx context.Context, searchQuery string, searchOptions SearchOptions) (string, error) {
+	data := searchOptions.values(searchQuery)
+
 	headers := map[string]string{
 		"Content-Type": "application/x-www-form-urlencoded",
 	}
 
-	resp, respCode, err := c.httpCall("POST", "/services/search/jobs", headers, []byte(data.Encode()))
+	resp, respCode, err := c.httpCall(ctx, "POST", c.servicePath("/search/jobs"), headers, []byte(data.Encode()))
 	if err != nil || respCode != http.StatusCreated {
-		return "", fmt.Errorf("unable to create search job %s %d %s", err, respCode, string(resp))
+		return "", &DispatchError{StatusCode: respCode, Body: string(resp), Err: err}
 	}
 
 	respStruct := struct {
@@ -111,13 +398,108 @@ func (c Connection) SearchJobCreate(searchQuery string, searchOptions SearchOpti
 	return respStruct.Sid, nil
 }
 
+// returned by SearchJobCreate when the search job could not be dispatched
+type DispatchError struct {
+	StatusCode int // 0 if the request failed
+	Body       string
+	Err        error
+}
+
+func (e *DispatchError) Error() string {
+	return fmt.Sprintf("unable to create search job %s %d %s", e.Err, e.StatusCode, e.Body)
+}
+
+func (e *DispatchError) Unwrap() error {
+	return e.Err
+}
+
+// messages splunk refuses to dispatch a search with for lack of quota, e.g.
+//
+//	Search not executed: The maximum number of concurrent historical searches for this user based on their role quota has been reached.
+//	The maximum number of concurrent historical searches on this instance has been reached.
+//	Search not executed: The minimum free disk space (5000MB) reached for /opt/splunk/var/run/splunk/dispatch.
+//	This search could not be dispatched because the role-based disk usage quota of search artifacts for user "admin" has been reached
+var quotaRegexps = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)maximum number of concurrent .*searches .*(?:has been|is) reached`),
+	regexp.MustCompile(`(?i)minimum free disk space \(\d+\s*MB\) reached`),
+	regexp.MustCompile(`(?i)disk usage quota .*has been reached`),
+}
+
+// whether splunk refused the search for lack of (concurrent search, disk) quota,
+// or throttled it (429, or 503 with Retry-After), so dispatching it again later may succeed.
+// a throttled dispatch wasn't processed by splunkd, so it is safe to send again
+func (e *DispatchError) IsQuota() bool {
+	if errors.Is(e.Err, ErrThrottled) {
+		return true
+	}
+
+	switch e.StatusCode {
+	case http.StatusBadRequest, http.StatusTooManyRequests, http.StatusServiceUnavailable:
+	default:
+		return false
+	}
+
+	for _, re := range quotaRegexps {
+		if re.MatchString(e.Body) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// dispatch a search job, retrying up to DispatchRetries times while splunk is out of quota or throttling it
+func (c Connection) dispatchSearchJob(ctx context.Context, searchQuery string, searchOptions SearchOptions) (string, error) {
+	wait := searchOptions.DispatchRetryWait
+	if wait <= 0 {
+		wait = SEARCH_WAIT * time.Second
+	}
+
+	for attempt := 0; ; attempt++ {
+		sid, err := c.createSearchJob(ctx, searchQuery, searchOptions)
+		var dispatchErr *DispatchError
+		if err == nil || attempt >= searchOptions.DispatchRetries ||
+			!errors.As(err, &dispatchErr) || !dispatchErr.IsQuota() {
+			return sid, err
+		}
+
+		log.Warn("search not dispatched, out of quota or throttled, requeueing",
+			"attempt", attempt+1,
+			"wait", wait,
+			"err", err)
+
+		if searchOptions.OnProgress != nil {
+			searchOptions.OnProgress(SearchJobStatus{
+				Messages: []SearchMessage{{
+					Type:    "WARN",
+					Message: fmt.Sprintf("%s, retrying in %s", err, wait),
+				}},
+				Entry: []SearchJobEntry{{
+					Content: SearchJobContent{DispatchState: "QUEUED"},
+				}},
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
 func (c Connection) SearchJobStatus(jobID string) (SearchJobStatus, error) {
+	return c.SearchJobStatusContext(context.Background(), jobID)
+}
+
+func (c Connection) SearchJobStatusContext(ctx context.Context, jobID string) (SearchJobStatus, error) {
 	data := make(url.Values)
 	data.Add("output_mode", "json")
 
-	resp, respCode, err := c.httpCall("GET", fmt.Sprintf("/services/search/jobs/%s", jobID), map[string]string{}, []byte(data.Encode()))
+	resp, respCode, err := c.httpCall(ctx, "GET", c.servicePath(fmt.Sprintf("/search/jobs/%s", jobID)), map[string]string{}, []byte(data.Encode()))
 	if err != nil || respCode != http.StatusOK {
-		return SearchJobStatus{}, fmt.Errorf("unable to create search job %s", err)
+		return SearchJobStatus{}, fmt.Errorf("unable to create search job %w", err)
 	}
 
 	var respStruct SearchJobStatus
@@ -129,147 +511,474 @@ func (c Connection) SearchJobStatus(jobID string) (SearchJobStatus, error) {
 }
 
 func (c Connection) SearchJobResults(jobID string) ([]map[string]interface{}, error) {
+	return c.SearchJobResultsContext(context.Background(), jobID)
+}
+
+func (c Connection) SearchJobResultsContext(ctx context.Context, jobID string) ([]map[string]interface{}, error) {
+	result, err := c.SearchJobResultsDetailedContext(ctx, jobID)
+	if err != nil {
+		return []map[string]interface{}{}, err
+	}
+
+	return result.Results, nil
+}
+
+// same as SearchJobResults, but returns the results wrapped in a SearchResult
+// along with the fields, messages and truncation information reported by splunk
+func (c Connection) SearchJobResultsDetailed(jobID string) (SearchResult, error) {
+	return c.SearchJobResultsDetailedContext(context.Background(), jobID)
+}
+
+func (c Connection) SearchJobResultsDetailedContext(ctx context.Context, jobID string) (SearchResult, error) {
+	result, err := c.searchJobResults(ctx, jobID, ResultsOptions{})
+	if err != nil {
+		return SearchResult{}, err
+	}
+
+	result.Results, err = c.transformResults(result.Results)
+	return result, err
+}
+
+func (c Connection) searchJobResults(ctx context.Context, jobID string, resultsOptions ResultsOptions) (SearchResult, error) {
+	data := resultsOptions.values()
+
+	resp, respCode, err := c.httpCall(ctx, "GET", c.servicePath(fmt.Sprintf("/search/jobs/%s/results?%s", jobID, data.Encode())), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return SearchResult{}, fmt.Errorf("unable to get search job results %w %d %s", err, respCode, string(resp))
+	}
+
+	respStruct := struct {
+		Fields   tableFields              `json:"fields"`
+		Results  []map[string]interface{} `json:"results"`
+		Messages []SearchMessage          `json:"messages"`
+	}{}
+	if err = json.Unmarshal(resp, &respStruct); err != nil {
+		return SearchResult{}, fmt.Errorf("unable to parse results from splunk: %s | response: %s", err, string(resp))
+	}
+
+	result := SearchResult{
+		Results:  respStruct.Results,
+		Fields:   respStruct.Fields,
+		Messages: respStruct.Messages,
+	}
+	result.Truncated, result.TruncatedAt = parseTruncation(respStruct.Messages)
+
+	return result, nil
+}
+
+// List the search jobs visible to the Connection's user (in the Connection's namespace)
+func (c Connection) SearchJobList() ([]SearchJobContent, error) {
+	return c.SearchJobListContext(context.Background())
+}
+
+func (c Connection) SearchJobListContext(ctx context.Context) ([]SearchJobContent, error) {
 	data := make(url.Values)
 	data.Add("output_mode", "json")
+	data.Add("count", "0")
 
-	resp, respCode, err := c.httpCall("GET", fmt.Sprintf("/services/search/jobs/%s/results", jobID), map[string]string{}, []byte(data.Encode()))
+	resp, respCode, err := c.httpCall(ctx, "GET", c.servicePath(fmt.Sprintf("/search/jobs?%s", data.Encode())), map[string]string{}, []byte{})
 	if err != nil || respCode != http.StatusOK {
-		return []map[string]interface{}{}, fmt.Errorf("unable to create search job %s", err)
+		return []SearchJobContent{}, fmt.Errorf("unable to list search jobs %w %d %s", err, respCode, string(resp))
+	}
+
+	var respStruct SearchJobStatus
+	if err = json.Unmarshal(resp, &respStruct); err != nil {
+		return []SearchJobContent{}, fmt.Errorf("unable to parse search jobs from splunk: %s | response: %s", err, string(resp))
+	}
+
+	jobs := make([]SearchJobContent, 0, len(respStruct.Entry))
+	for _, e := range respStruct.Entry {
+		jobs = append(jobs, e.Content)
+	}
+
+	return jobs, nil
+}
+
+// Get the raw events of a search job, as opposed to SearchJobResults
+// which returns the transformed results
+func (c Connection) SearchJobEvents(jobID string, resultsOptions ResultsOptions) ([]map[string]interface{}, error) {
+	return c.SearchJobEventsContext(context.Background(), jobID, resultsOptions)
+}
+
+func (c Connection) SearchJobEventsContext(ctx context.Context, jobID string, resultsOptions ResultsOptions) ([]map[string]interface{}, error) {
+	events, err := c.searchJobEvents(ctx, jobID, resultsOptions)
+	if err != nil {
+		return []map[string]interface{}{}, err
+	}
+
+	return c.transformResults(events)
+}
+
+func (c Connection) searchJobEvents(ctx context.Context, jobID string, resultsOptions ResultsOptions) ([]map[string]interface{}, error) {
+	data := resultsOptions.values()
+
+	resp, respCode, err := c.httpCall(ctx, "GET", c.servicePath(fmt.Sprintf("/search/jobs/%s/events?%s", jobID, data.Encode())), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return []map[string]interface{}{}, fmt.Errorf("unable to get search job events %w %d %s", err, respCode, string(resp))
 	}
 
 	respStruct := struct {
 		Results []map[string]interface{} `json:"results"`
 	}{}
 	if err = json.Unmarshal(resp, &respStruct); err != nil {
-		return []map[string]interface{}{}, fmt.Errorf("unable to parse sid from splunk: %s | response: %s", err, string(resp))
+		return []map[string]interface{}{}, fmt.Errorf("unable to parse events from splunk: %s | response: %s", err, string(resp))
 	}
 
 	return respStruct.Results, nil
 }
 
+// Get the preview results of a search job while it is still running
+func (c Connection) SearchJobResultsPreview(jobID string, resultsOptions ResultsOptions) ([]map[string]interface{}, error) {
+	return c.SearchJobResultsPreviewContext(context.Background(), jobID, resultsOptions)
+}
+
+func (c Connection) SearchJobResultsPreviewContext(ctx context.Context, jobID string, resultsOptions ResultsOptions) ([]map[string]interface{}, error) {
+	preview, err := c.searchJobResultsPreview(ctx, jobID, resultsOptions)
+	if err != nil {
+		return []map[string]interface{}{}, err
+	}
+
+	return c.transformResults(preview)
+}
+
+func (c Connection) searchJobResultsPreview(ctx context.Context, jobID string, resultsOptions ResultsOptions) ([]map[string]interface{}, error) {
+	data := resultsOptions.values()
+
+	resp, respCode, err := c.httpCall(ctx, "GET", c.servicePath(fmt.Sprintf("/search/jobs/%s/results_preview?%s", jobID, data.Encode())), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return []map[string]interface{}{}, fmt.Errorf("unable to get search job results preview %w %d %s", err, respCode, string(resp))
+	}
+
+	respStruct := struct {
+		Results []map[string]interface{} `json:"results"`
+	}{}
+	if err = json.Unmarshal(resp, &respStruct); err != nil {
+		return []map[string]interface{}{}, fmt.Errorf("unable to parse results preview from splunk: %s | response: %s", err, string(resp))
+	}
+
+	return respStruct.Results, nil
+}
+
+func (c Connection) SearchJobDelete(jobID string) error {
+	return c.SearchJobDeleteContext(context.Background(), jobID)
+}
+
+func (c Connection) SearchJobDeleteContext(ctx context.Context, jobID string) error {
+	resp, respCode, err := c.httpCall(ctx, "DELETE", c.servicePath(fmt.Sprintf("/search/jobs/%s", jobID)), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return fmt.Errorf("unable to delete search job %w %d %s", err, respCode, string(resp))
+	}
+
+	return nil
+}
+
 // Blocking Search function
-// this will queue a search job, and wait in SEARCH_WAIT increments to check
-// search-job status, and then return the result records
+// this will queue a search job, and wait in SearchOptions.PollInterval
+// (SEARCH_WAIT by default) increments to check search-job status,
+// and then return the result records
 func (c Connection) Search(searchQuery string, searchOptions SearchOptions) ([]map[string]interface{}, error) {
-	return c.search(searchQuery, searchOptions, 0)
+	return c.SearchContext(context.Background(), searchQuery, searchOptions)
 }
 
-func (c Connection) search(searchQuery string, searchOptions SearchOptions, partitionLevel int) ([]map[string]interface{}, error) {
+// same as Search, the search job is cancelled and the poll loop stops
+// as soon as ctx is done
+func (c Connection) SearchContext(ctx context.Context, searchQuery string, searchOptions SearchOptions) ([]map[string]interface{}, error) {
+	result, err := c.search(ctx, searchQuery, searchOptions, 0)
+	if err != nil {
+		return []map[string]interface{}{}, err
+	}
+
+	return result.Results, nil
+}
+
+// same as Search, but returns the results wrapped in a SearchResult, with the job's
+// result count and whether the results are (likely) incomplete
+func (c Connection) SearchDetailed(searchQuery string, searchOptions SearchOptions) (SearchResult, error) {
+	return c.SearchDetailedContext(context.Background(), searchQuery, searchOptions)
+}
+
+func (c Connection) SearchDetailedContext(ctx context.Context, searchQuery string, searchOptions SearchOptions) (SearchResult, error) {
+	return c.search(ctx, searchQuery, searchOptions, 0)
+}
+
+func (c Connection) search(ctx context.Context, searchQuery string, searchOptions SearchOptions, partitionLevel int) (SearchResult, error) {
 
 	if searchOptions.MaxCount == 0 {
 		searchOptions.MaxCount = DEFAULT_MAX_COUNT
 	}
 
-	sid, err := c.SearchJobCreate(searchQuery, searchOptions)
-	if err != nil {
-		return []map[string]interface{}{}, err
+	if searchOptions.Timeout > 0 && partitionLevel == 0 {
+		// the deadline covers the whole search, including partitioned searches
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, searchOptions.Timeout)
+		defer cancel()
 	}
 
-	waiting := true
-	for waiting {
-		jobStatus, err := c.SearchJobStatus(sid)
+	if partitionLevel == 0 && searchOptions.PartitionStrategy == DensityPartition &&
+		searchOptions.AllowPartition && searchOptions.UseEarliestTime && searchOptions.UseLatestTime {
+		windows, err := c.densityPartitionWindows(ctx, searchQuery, searchOptions)
 		if err != nil {
-			return []map[string]interface{}{}, err
+			return SearchResult{}, err
 		}
-
-		isDone, err := jobStatus.IsDone()
-		if err != nil {
-			return []map[string]interface{}{}, err
+		if len(windows) > 1 {
+			return c.searchPartitioned(ctx, searchQuery, searchOptions, partitionLevel, windows)
 		}
+	}
 
-		if isDone {
-			waiting = false
-			break
-		}
+	// the slot is held while the job runs, not while its results are fetched
+	// or its partitions run, which would deadlock on a full limiter
+	release, err := c.acquireSearchSlot(ctx)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	// released once the job is done, deferred too so a panicking callback doesn't keep the slot
+	defer release()
 
-		time.Sleep(SEARCH_WAIT * time.Second)
+	jobOptions := searchOptions
+	if searchOptions.partitionTracker != nil {
+		jobOptions.OnProgress = searchOptions.partitionTracker.onProgress(searchOptions.partitionID)
 	}
 
-	results, err := c.SearchJobResults(sid)
+	sid, err := c.dispatchSearchJob(ctx, searchQuery, jobOptions)
 	if err != nil {
-		return []map[string]interface{}{}, err
+		return SearchResult{}, err
+	}
+
+	err = c.waitJobOrCancel(ctx, sid, jobOptions)
+	release()
+	if err != nil {
+		return SearchResult{}, err
+	}
+
+	result, err := c.searchJobResultsAll(ctx, sid)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	if searchOptions.partitionTracker != nil {
+		searchOptions.partitionTracker.fetched(searchOptions.partitionID, len(result.Results))
 	}
 
-	if len(results) == searchOptions.MaxCount {
+	// the job's result count, as ResultTransforms may have dropped rows
+	if result.ResultCount == searchOptions.MaxCount {
 
 		log.Warn("number of records returned equal to max count")
+		if searchOptions.AllowPartition && partitionLevel == 0 &&
+			searchOptions.PartitionStrategy == ServerPartition && !strings.HasPrefix(strings.TrimSpace(searchQuery), "|") {
+			windows, err := c.serverPartitionWindows(ctx, searchOptions)
+			if err != nil {
+				return SearchResult{}, err
+			}
+			if len(windows) > 1 {
+				return c.searchPartitioned(ctx, searchQuery, searchOptions, partitionLevel, windows)
+			}
+		}
+
 		if searchOptions.AllowPartition &&
 			searchOptions.UseEarliestTime &&
 			searchOptions.UseLatestTime {
 			// max count of returned results
 			// partition the search time range
-			d := math.Ceil((searchOptions.LatestTime.Sub(searchOptions.EarliestTime).Seconds()) / PARTITION_COUNT)
+			rangeSeconds := searchOptions.LatestTime.Sub(searchOptions.EarliestTime).Seconds()
+			if partitionLevel >= searchOptions.maxPartitionDepth() || rangeSeconds < 2 {
+				// time bounds have a one second resolution, can't split any further
+				return result, &PartitionDensityError{
+					EarliestTime: searchOptions.EarliestTime,
+					LatestTime:   searchOptions.LatestTime,
+					MaxCount:     searchOptions.MaxCount,
+					Depth:        partitionLevel,
+				}
+			}
 
-			startT := searchOptions.EarliestTime
-			endT := searchOptions.EarliestTime
+			partitionCount := 2
+			if partitionLevel == 0 {
+				partitionCount = searchOptions.partitionCount(result)
+			}
+			if partitionCount > int(rangeSeconds) {
+				partitionCount = int(rangeSeconds)
+			}
 
-			var wg sync.WaitGroup
+			return c.searchPartitioned(ctx, searchQuery, searchOptions, partitionLevel,
+				partitionWindows(searchOptions.EarliestTime, searchOptions.LatestTime, partitionCount))
+		}
 
-			partitionedResults := make(map[int][]map[string]interface{})
-			partitionedErr := make(map[int]error)
-			for i := 0; i < PARTITION_COUNT; i++ {
-				endT = startT.Add(time.Duration(d) * time.Second)
+		// could not partition, the results are (likely) cut off at max count
+		result.Truncated = true
+		result.TruncatedAt = searchOptions.MaxCount
+	}
 
-				if partitionLevel <= 6 { // partitionLevel = 6 , 15625 goroutines could be spawned,
-					wg.Add(1)
-					go func(idx int, start, end time.Time) {
-						defer wg.Done()
+	if searchOptions.onResults != nil {
+		if err := searchOptions.onResults(result); err != nil {
+			return SearchResult{}, err
+		}
+		result.Results = []map[string]interface{}{}
+	}
 
-						log.Debug("partition",
-							"i", idx,
-							"start", start.Format(TIME_FORMAT),
-							"end", end.Format(TIME_FORMAT),
-						)
-						partitionSearchOptions := searchOptions
+	return result, nil
+}
 
-						partitionSearchOptions.EarliestTime = start
-						partitionSearchOptions.LatestTime = end
+// poll a search job until it is done, calling the OnProgress/OnPreview callbacks of searchOptions.
+// returns an error if the job failed, or waiting on it failed,
+// in which case the job is cancelled if cancelOnAbandon is set
+func (c Connection) waitSearchJob(ctx context.Context, sid string, searchOptions SearchOptions, cancelOnAbandon bool) error {
+	pollInterval := searchOptions.pollInterval()
+	previewOffset := 0
 
-						rec, err := c.search(searchQuery, partitionSearchOptions, partitionLevel+1)
-						partitionedErr[idx] = err
-						partitionedResults[idx] = rec
-					}(i, startT, endT)
-				} else {
-					// partitionLevel = 7 , 78125 goroutines could be spawned,
-					//  stop spawning more goroutines, and make searches sequentially
-					log.Debug("partition",
-						"async", false,
-						"i", i,
-						"start", startT.Format(TIME_FORMAT),
-						"end", endT.Format(TIME_FORMAT),
-					)
-
-					partitionSearchOptions := searchOptions
-
-					partitionSearchOptions.EarliestTime = startT
-					partitionSearchOptions.LatestTime = endT
-
-					rec, err := c.search(searchQuery, partitionSearchOptions, partitionLevel+1)
-					partitionedErr[i] = err
-					partitionedResults[i] = rec
-				}
+	wake := c.jobWake(sid)
+	defer func() {
+		jobWakers.CompareAndDelete(c.jobWakeKey(sid), wake)
+	}()
+
+	waiting := true
+	for waiting {
+		jobStatus, err := c.SearchJobStatusContext(ctx, sid)
+		if err != nil {
+			if cancelOnAbandon {
+				c.cancelAbandonedJob(sid)
+			}
+			return searchWaitError(ctx, sid, searchOptions, err)
+		}
+
+		if searchOptions.OnProgress != nil {
+			searchOptions.OnProgress(jobStatus)
+		}
+
+		isDone, err := jobStatus.IsDone()
+		if err != nil {
+			return err
+		}
+
+		if isDone {
+			waiting = false
+			break
+		}
 
-				startT = endT
+		if searchOptions.OnPreview != nil {
+			previewOptions := ResultsOptions{}
+			if searchOptions.IncrementalPreview {
+				previewOptions.Offset = previewOffset
 			}
 
-			// wait for partitioned searches to be completed
-			wg.Wait()
+			preview, err := c.searchJobResultsPreview(ctx, sid, previewOptions)
+			if err != nil {
+				log.Warn("unable to get search job results preview", "sid", sid, "err", err)
+			} else if !searchOptions.IncrementalPreview || len(preview) > 0 {
+				previewOffset += len(preview)
 
-			results = make([]map[string]interface{}, 0, PARTITION_COUNT*searchOptions.MaxCount)
-			for idx, res := range partitionedResults {
-				if partitionedErr[idx] != nil {
-					return results, partitionedErr[idx]
+				preview, err = c.transformResults(preview)
+				if err != nil {
+					log.Warn("unable to transform search job results preview", "sid", sid, "err", err)
+				} else {
+					searchOptions.OnPreview(preview)
 				}
+			}
+		}
 
-				log.Debug("partition results", "idx", idx, "count", len(res))
-				results = append(results, res...)
+		if err := pollWait(ctx, pollInterval, wake); err != nil {
+			if cancelOnAbandon {
+				c.cancelAbandonedJob(sid)
 			}
+			return searchWaitError(ctx, sid, searchOptions, err)
+		}
+		pollInterval = searchOptions.nextPollInterval(pollInterval)
+
+		select {
+		case <-wake:
+			// woken up, wait on the next state change
+			wake = c.jobWake(sid)
+		default:
+		}
+	}
+
+	return nil
+}
+
+// wait on the job search dispatched, cancelling it if a callback (OnProgress, OnPreview)
+// panics, as the panic abandons the job
+func (c Connection) waitJobOrCancel(ctx context.Context, sid string, searchOptions SearchOptions) error {
+	defer func() {
+		if r := recover(); r != nil {
+			c.cancelAbandonedJob(sid)
+			panic(r)
+		}
+	}()
+
+	return c.waitSearchJob(ctx, sid, searchOptions, true)
+}
+
+// custom sid of a partition's job, so partitions don't collide with the parent job's sid
+func partitionJobID(jobID string, partition int) string {
+	if jobID == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s_p%d", jobID, partition)
+}
 
-			return results, nil
+// cancel a job the blocking search gave up waiting on,
+// so it doesn't keep running on the search head.
+// this deliberately doesn't take the search context, which is likely done
+func (c Connection) cancelAbandonedJob(sid string) {
+	if err := c.SearchJobCancel(sid); err != nil {
+		log.Warn("unable to cancel abandoned search job", "sid", sid, "err", err)
+	}
+}
+
+// error to return when waiting on a search job failed,
+// a *SearchTimeoutError if it failed because the deadline was exceeded
+func searchWaitError(ctx context.Context, sid string, searchOptions SearchOptions, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &SearchTimeoutError{
+			Sid:     sid,
+			Timeout: searchOptions.Timeout,
 		}
 	}
 
-	return results, nil
+	return err
+}
+
+// sleep for d plus a random jitter of up to SEARCH_WAIT_JITTER * d,
+// so partitioned searches launched together don't poll in lockstep.
+// returns early with the context error if ctx is done before the wait is over,
+// or without error when wake is closed
+func pollWait(ctx context.Context, d time.Duration, wake <-chan struct{}) error {
+	jitter := int64(float64(d) * SEARCH_WAIT_JITTER)
+	if jitter > 0 {
+		d += time.Duration(rand.Int63n(jitter))
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-wake:
+		return nil
+	case <-t.C:
+		return nil
+	}
+}
+
+// wake-up channels of the jobs being waited on, keyed by host and sid.
+// shared by copies of a Connection, as Connection is passed by value
+var jobWakers sync.Map
+
+func (c Connection) jobWakeKey(sid string) string {
+	return fmt.Sprintf("%s|%s", c.Host, sid)
+}
+
+// channel closed when sid's state is changed through a Connection
+// (e.g. finalized, paused or cancelled), so waiters poll it right away
+func (c Connection) jobWake(sid string) chan struct{} {
+	wake, _ := jobWakers.LoadOrStore(c.jobWakeKey(sid), make(chan struct{}))
+	return wake.(chan struct{})
+}
+
+// wake the waiters on sid
+func (c Connection) wakeJobWaiters(sid string) {
+	if wake, ok := jobWakers.LoadAndDelete(c.jobWakeKey(sid)); ok {
+		close(wake.(chan struct{}))
+	}
 }
 
 // Stub function making it easier to search in an Async fashion as a goroutine
@@ -0,0 +1,34 @@
+package go_splunk_rest
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDispatchThrottled(t *testing.T) {
+	dispatches := 0
+	handler := searchHandler(t, "test", 1)
+	c := newTestConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" && r.URL.Path == "/services/search/jobs" {
+			dispatches++
+			if dispatches == 1 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+		}
+		handler(w, r)
+	})
+
+	results, err := c.Search("search index=main", SearchOptions{
+		JobID:             "test",
+		DispatchRetries:   1,
+		DispatchRetryWait: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dispatches != 2 || len(results) != 1 {
+		t.Fatalf("got %d dispatches and %d results, want the throttled dispatch requeued", dispatches, len(results))
+	}
+}
@@ -0,0 +1,238 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	log "log/slog"
+)
+
+// sessionKey valid for one hour by default, and timer resets after every use
+const SESSION_KEY_TTL = time.Hour
+
+type SessionRefresh string
+
+// the session key expires SessionTTL after it was last used, as splunkd's sessions do (default)
+const SlidingSession SessionRefresh = "sliding"
+
+// the session key is replaced SessionTTL after logging in, however often it is used,
+// e.g. for servers (or proxies) enforcing an absolute session lifetime
+const FixedSession SessionRefresh = "fixed"
+
+// session key of a Connection, logging in again when it expires.
+// concurrent callers needing a new session key wait on a single login
+type sessionManager struct {
+	mu         sync.Mutex
+	key        string
+	loggedInAt time.Time
+	lastUsed   time.Time
+	refreshing *sessionLogin // login in flight, nil if none
+}
+
+// how long a session key is used, from the Connection's SessionTTL and SessionRefresh.
+// passed in by the caller, as copies of a Connection sharing a sessionManager may differ
+type sessionPolicy struct {
+	ttl   time.Duration
+	fixed bool
+}
+
+func (c Connection) sessionPolicy() sessionPolicy {
+	return sessionPolicy{
+		ttl:   c.sessionTTL(),
+		fixed: c.SessionRefresh == FixedSession,
+	}
+}
+
+// a login in flight, done is closed once key and err are set
+type sessionLogin struct {
+	done chan struct{}
+	key  string
+	err  error
+}
+
+// session managers shared by the Connections with the same Host and credentials,
+// so they log in once and reuse the session key
+var sessionManagers sync.Map
+
+func (c Connection) sessionManagerKey() string {
+	// a Connection with another password mustn't reuse the session key
+	return fmt.Sprintf("%s|%s|%s", c.Host, c.Username, secretFingerprint(c.Password))
+}
+
+// copy of the Connection with a session manager of its own, shared by the copies
+// made from it (e.g. by AsUser) but not with other Connections for the same Host and Username,
+// e.g. to keep separate sessions
+func (c Connection) WithSession() Connection {
+	c.sessions = &sessionManager{}
+	return c
+}
+
+func (c Connection) session() *sessionManager {
+	if c.sessions != nil {
+		return c.sessions
+	}
+
+	s, _ := sessionManagers.LoadOrStore(c.sessionManagerKey(), &sessionManager{})
+	return s.(*sessionManager)
+}
+
+func (c Connection) sessionTTL() time.Duration {
+	if c.SessionTTL > 0 {
+		return c.SessionTTL
+	}
+
+	return SESSION_KEY_TTL
+}
+
+// whether the session key is still valid under policy, s.mu must be held
+func (s *sessionManager) valid(policy sessionPolicy) bool {
+	if s.key == "" {
+		return false
+	}
+	if policy.fixed {
+		return time.Since(s.loggedInAt) < policy.ttl
+	}
+
+	return time.Since(s.lastUsed) < policy.ttl
+}
+
+// get a valid session key, calling login if there is none.
+// callers waiting on a login in flight get its session key or error
+func (s *sessionManager) sessionKey(ctx context.Context, policy sessionPolicy, login func(context.Context) (string, error)) (string, error) {
+	for {
+		s.mu.Lock()
+		if s.valid(policy) {
+			s.lastUsed = time.Now()
+			key := s.key
+			s.mu.Unlock()
+			return key, nil
+		}
+
+		call := s.refreshing
+		if call == nil {
+			break
+		}
+
+		// another caller is logging in, use its outcome
+		s.mu.Unlock()
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		if call.err != nil && ctx.Err() == nil &&
+			(errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded)) {
+			// the login was abandoned by its caller, not refused
+			continue
+		}
+
+		return call.key, call.err
+	}
+
+	// s.mu is held
+	call := &sessionLogin{done: make(chan struct{})}
+	s.refreshing = call
+	s.mu.Unlock()
+
+	call.key, call.err = login(ctx)
+
+	s.mu.Lock()
+	if call.err == nil {
+		s.key = call.key
+		s.loggedInAt = time.Now()
+		s.lastUsed = s.loggedInAt
+	}
+	s.refreshing = nil
+	close(call.done)
+	s.mu.Unlock()
+
+	return call.key, call.err
+}
+
+// the session key if there is a valid one, without logging in
+func (s *sessionManager) current(policy sessionPolicy) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.valid(policy) {
+		return ""
+	}
+
+	return s.key
+}
+
+// drop the session key, so the next request logs in again
+func (s *sessionManager) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.key = ""
+}
+
+// drop sessionKey, rejected by splunk, locally and from the SessionStore,
+// unless it was already replaced by a new login
+func (c Connection) invalidateSession(ctx context.Context, sessionKey string) {
+	c, err := c.withCredentials(ctx)
+	if err != nil {
+		return
+	}
+
+	session := c.session()
+	session.mu.Lock()
+	if session.key == sessionKey {
+		session.key = ""
+	}
+	session.mu.Unlock()
+
+	if c.SessionStore != nil {
+		stored, err := c.SessionStore.Load(c.sessionStoreKey())
+		if err == nil && stored.SessionKey == sessionKey {
+			if err := c.SessionStore.Save(c.sessionStoreKey(), StoredSession{}); err != nil {
+				log.Warn("unable to clear session from store", "err", err)
+			}
+		}
+	}
+}
+
+// End the Connection's session (AuthorizationTokenAuth), deleting the session key on splunk,
+// and dropping it locally and from the SessionStore. the next request logs in again.
+// does nothing if there is no session
+func (c Connection) Logout() error {
+	return c.LogoutContext(context.Background())
+}
+
+func (c Connection) LogoutContext(ctx context.Context) error {
+	if c.AuthType != AuthorizationTokenAuth {
+		return nil
+	}
+
+	c, err := c.withCredentials(ctx)
+	if err != nil {
+		return err
+	}
+
+	session := c.session()
+	sessionKey := session.current(c.sessionPolicy())
+	if sessionKey == "" {
+		return nil
+	}
+
+	resp, respCode, err := c.httpCall(ctx, "DELETE", fmt.Sprintf("/services/authentication/httpauth-tokens/%s", url.PathEscape(sessionKey)), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return fmt.Errorf("unable to logout %w %d %s", err, respCode, string(resp))
+	}
+
+	session.invalidate()
+	if c.SessionStore != nil {
+		if err := c.SessionStore.Save(c.sessionStoreKey(), StoredSession{}); err != nil {
+			return fmt.Errorf("unable to clear session from store: %s", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package go_splunk_rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSessionReuse(t *testing.T) {
+	logins := 0
+	c := newTestConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/services/auth/login" {
+			logins++
+			fmt.Fprint(w, `{"sessionKey": "test-session"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Splunk test-session" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"entry": [{"content": {"sid": "1234.5", "isDone": true}}]}`)
+	})
+	c.AuthType = AuthorizationTokenAuth
+	c.Username = "admin"
+	c.Password = "changeme"
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.SearchJobStatus("1234.5"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if logins != 1 {
+		t.Fatalf("got %d logins, want the session key reused", logins)
+	}
+
+	if _, err := c.WithSession().SearchJobStatus("1234.5"); err != nil {
+		t.Fatal(err)
+	}
+	if logins != 2 {
+		t.Fatalf("got %d logins, want WithSession to log in on its own", logins)
+	}
+}
+
+func TestLoginRejected(t *testing.T) {
+	logins := 0
+	c := newTestConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/services/auth/login" {
+			logins++
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	c.AuthType = AuthorizationTokenAuth
+	c.Username = "admin"
+	c.Password = "wrong"
+	c.RetryMaxAttempts = 3
+	c.RetryWait = time.Millisecond
+
+	if _, err := c.SearchJobStatus("1234.5"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("got %v, want ErrUnauthorized", err)
+	}
+	if logins != 1 {
+		t.Fatalf("got %d logins, want the rejected login not retried", logins)
+	}
+}
@@ -0,0 +1,152 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "log/slog"
+)
+
+// a session key persisted in a SessionStore
+type StoredSession struct {
+	SessionKey string    `json:"session_key"`
+	LoggedInAt time.Time `json:"logged_in_at"`
+}
+
+// persists session keys of AuthorizationTokenAuth Connections across processes,
+// keyed by host and username. Load returns a zero StoredSession when there is none,
+// and saving a zero StoredSession (e.g. on Logout) removes the session
+type SessionStore interface {
+	Load(key string) (StoredSession, error)
+	Save(key string, session StoredSession) error
+}
+
+// SessionStore keeping session keys in a JSON file, readable only by the owner.
+// the file is replaced atomically, so processes sharing it never read a partial write,
+// though concurrent saves from several processes may drop each other's sessions
+type FileSessionStore struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (s *FileSessionStore) Load(key string) (StoredSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions, err := s.read()
+	if err != nil {
+		return StoredSession{}, err
+	}
+
+	return sessions[key], nil
+}
+
+func (s *FileSessionStore) Save(key string, session StoredSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions, err := s.read()
+	if err != nil {
+		// start over rather than failing every save, the sessions are only a cache
+		log.Warn("unable to read session store, replacing it", "path", s.Path, "err", err)
+		sessions = make(map[string]StoredSession)
+	}
+	if session.SessionKey == "" {
+		delete(sessions, key)
+	} else {
+		sessions[key] = session
+	}
+
+	data, err := json.Marshal(sessions)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.Path, data, 0600)
+}
+
+// write data to a temporary file next to path and rename it over path,
+// so readers see either the old or the new content
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // after a successful rename there is nothing to remove
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *FileSessionStore) read() (map[string]StoredSession, error) {
+	sessions := make(map[string]StoredSession)
+
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return sessions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &sessions); err != nil {
+		return nil, fmt.Errorf("unable to parse session store %s: %s", s.Path, err)
+	}
+
+	return sessions, nil
+}
+
+func (c Connection) sessionStoreKey() string {
+	return fmt.Sprintf("%s|%s", c.Host, c.Username)
+}
+
+// get a session key from SessionStore if it has a recent enough one,
+// otherwise log in and save the new session key to it
+func (c Connection) loginSession(ctx context.Context) (string, error) {
+	if c.SessionStore == nil {
+		return c.getSessionKey(ctx)
+	}
+
+	stored, err := c.SessionStore.Load(c.sessionStoreKey())
+	if err != nil {
+		log.Warn("unable to load session from store", "err", err)
+	} else if stored.SessionKey != "" && time.Since(stored.LoggedInAt) < c.sessionTTL() {
+		return stored.SessionKey, nil
+	}
+
+	sessionKey, err := c.getSessionKey(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.SessionStore.Save(c.sessionStoreKey(), StoredSession{
+		SessionKey: sessionKey,
+		LoggedInAt: time.Now(),
+	})
+	if err != nil {
+		log.Warn("unable to save session to store", "err", err)
+	}
+
+	return sessionKey, nil
+}
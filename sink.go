@@ -0,0 +1,151 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// destination for search results, written to one result at a time.
+// Flush is called once all results have been written
+type ResultSink interface {
+	WriteResult(map[string]interface{}) error
+	Flush() error
+}
+
+// Run a search through the streaming export endpoint, writing the results to sink
+// as they are received, and flushing it at the end
+func (c Connection) SearchExportToSink(searchQuery string, searchOptions SearchOptions, sink ResultSink) error {
+	return c.SearchExportToSinkContext(context.Background(), searchQuery, searchOptions, sink)
+}
+
+func (c Connection) SearchExportToSinkContext(ctx context.Context, searchQuery string, searchOptions SearchOptions, sink ResultSink) error {
+	if err := c.SearchExportContext(ctx, searchQuery, searchOptions, sink.WriteResult); err != nil {
+		return err
+	}
+
+	return sink.Flush()
+}
+
+// Run a blocking Search, writing the results to sink and flushing it at the end
+func (c Connection) SearchToSink(searchQuery string, searchOptions SearchOptions, sink ResultSink) error {
+	return c.SearchToSinkContext(context.Background(), searchQuery, searchOptions, sink)
+}
+
+func (c Connection) SearchToSinkContext(ctx context.Context, searchQuery string, searchOptions SearchOptions, sink ResultSink) error {
+	results, err := c.SearchContext(ctx, searchQuery, searchOptions)
+	if err != nil {
+		return err
+	}
+
+	for _, res := range results {
+		if err := sink.WriteResult(res); err != nil {
+			return err
+		}
+	}
+
+	return sink.Flush()
+}
+
+// ResultSink collecting results in memory
+type SliceSink struct {
+	Results []map[string]interface{}
+}
+
+func (s *SliceSink) WriteResult(res map[string]interface{}) error {
+	s.Results = append(s.Results, res)
+	return nil
+}
+
+func (s *SliceSink) Flush() error {
+	return nil
+}
+
+// ResultSink sending results on a channel, blocking until they are received
+// or ctx is done. the channel is not closed on Flush
+type ChanSink struct {
+	ctx context.Context
+	ch  chan<- Result
+}
+
+func NewChanSink(ctx context.Context, ch chan<- Result) *ChanSink {
+	return &ChanSink{ctx: ctx, ch: ch}
+}
+
+func (s *ChanSink) WriteResult(res map[string]interface{}) error {
+	select {
+	case s.ch <- Result(res):
+		return nil
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+func (s *ChanSink) Flush() error {
+	return nil
+}
+
+// ResultSink writing results to w as json lines.
+// Flush flushes w if it has a Flush() error method (e.g. *bufio.Writer)
+type JSONLSink struct {
+	w       io.Writer
+	encoder *json.Encoder
+}
+
+func NewJSONLSink(w io.Writer) *JSONLSink {
+	return &JSONLSink{w: w, encoder: json.NewEncoder(w)}
+}
+
+func (s *JSONLSink) WriteResult(res map[string]interface{}) error {
+	return s.encoder.Encode(res)
+}
+
+func (s *JSONLSink) Flush() error {
+	if f, ok := s.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+
+	return nil
+}
+
+// ResultSink buffering results and handing them to insert in batches of size,
+// e.g. for batched database inserts. the last partial batch is inserted on Flush
+type BatchSink struct {
+	size   int
+	insert func([]map[string]interface{}) error
+	batch  []map[string]interface{}
+}
+
+func NewBatchSink(size int, insert func([]map[string]interface{}) error) *BatchSink {
+	if size <= 0 {
+		size = 1
+	}
+
+	return &BatchSink{
+		size:   size,
+		insert: insert,
+		batch:  make([]map[string]interface{}, 0, size),
+	}
+}
+
+func (s *BatchSink) WriteResult(res map[string]interface{}) error {
+	s.batch = append(s.batch, res)
+	if len(s.batch) >= s.size {
+		return s.Flush()
+	}
+
+	return nil
+}
+
+func (s *BatchSink) Flush() error {
+	if len(s.batch) == 0 {
+		return nil
+	}
+
+	if err := s.insert(s.batch); err != nil {
+		return err
+	}
+	s.batch = make([]map[string]interface{}, 0, s.size)
+
+	return nil
+}
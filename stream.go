@@ -0,0 +1,37 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"errors"
+)
+
+// sequence of search results, with the same signature as
+// iter.Seq2[map[string]interface{}, error] so it can be ranged over (go 1.23+).
+// a non nil error is yielded at most once, as the last element
+type ResultSeq func(yield func(map[string]interface{}, error) bool)
+
+var errStopStream = errors.New("result stream stopped")
+
+// Run a search through the streaming export endpoint, returning an iterator
+// that decodes results one at a time from the response body as it is ranged over,
+// so memory use is bounded regardless of the size of the result set.
+// the search is only dispatched when the iteration starts, and every
+// iteration dispatches it again
+func (c Connection) SearchStream(searchQuery string, searchOptions SearchOptions) ResultSeq {
+	return c.SearchStreamContext(context.Background(), searchQuery, searchOptions)
+}
+
+func (c Connection) SearchStreamContext(ctx context.Context, searchQuery string, searchOptions SearchOptions) ResultSeq {
+	return func(yield func(map[string]interface{}, error) bool) {
+		err := c.SearchExportContext(ctx, searchQuery, searchOptions, func(res map[string]interface{}) error {
+			if !yield(res, nil) {
+				return errStopStream
+			}
+			return nil
+		})
+
+		if err != nil && !errors.Is(err, errStopStream) {
+			yield(nil, err)
+		}
+	}
+}
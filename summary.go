@@ -0,0 +1,102 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// field summary of a search job's events
+// more details can be found here:
+// https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTsearch#search.2Fjobs.2F.7Bsearch_id.7D.2Fsummary
+type SearchJobSummary struct {
+	EventCount   int     `json:"event_count"`
+	EarliestTime string  `json:"earliest_time"`
+	LatestTime   string  `json:"latest_time"`
+	Duration     float64 `json:"duration"`
+
+	Fields map[string]FieldSummary `json:"fields"`
+}
+
+type FieldSummary struct {
+	Count         int     `json:"count"`
+	DistinctCount int     `json:"distinct_count"`
+	NumericCount  int     `json:"numeric_count"`
+	IsExact       bool    `json:"is_exact"`
+	Min           float64 `json:"min"`
+	Max           float64 `json:"max"`
+	Mean          float64 `json:"mean"`
+	Stdev         float64 `json:"stdev"`
+
+	// most frequent values of the field
+	Modes []struct {
+		Value   string `json:"value"`
+		Count   int    `json:"count"`
+		IsExact bool   `json:"is_exact"`
+	} `json:"modes"`
+}
+
+// event distribution of a search job over time
+// more details can be found here:
+// https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTsearch#search.2Fjobs.2F.7Bsearch_id.7D.2Ftimeline
+type SearchJobTimeline struct {
+	EventCount int     `json:"event_count"`
+	CursorTime float64 `json:"cursor_time"`
+
+	Buckets []struct {
+		EarliestTime     float64 `json:"earliest_time"`
+		EarliestStrftime string  `json:"earliest_strftime"`
+		Duration         float64 `json:"duration"`
+		TotalCount       int     `json:"total_count"`
+		AvailableCount   int     `json:"available_count"`
+		IsFinalized      bool    `json:"is_finalized"`
+	} `json:"buckets"`
+}
+
+// Get the field summary of a search job,
+// the job must have been created with status buckets enabled for a summary to be available
+func (c Connection) SearchJobSummary(jobID string) (SearchJobSummary, error) {
+	return c.SearchJobSummaryContext(context.Background(), jobID)
+}
+
+func (c Connection) SearchJobSummaryContext(ctx context.Context, jobID string) (SearchJobSummary, error) {
+	data := make(url.Values)
+	data.Add("output_mode", "json")
+
+	resp, respCode, err := c.httpCall(ctx, "GET", c.servicePath(fmt.Sprintf("/search/jobs/%s/summary?%s", jobID, data.Encode())), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return SearchJobSummary{}, fmt.Errorf("unable to get search job summary %w %d %s", err, respCode, string(resp))
+	}
+
+	var respStruct SearchJobSummary
+	if err = json.Unmarshal(resp, &respStruct); err != nil {
+		return SearchJobSummary{}, fmt.Errorf("unable to parse summary from splunk: %s | response: %s", err, string(resp))
+	}
+
+	return respStruct, nil
+}
+
+// Get the event distribution buckets of a search job,
+// the job must have been created with status buckets enabled for a timeline to be available
+func (c Connection) SearchJobTimeline(jobID string) (SearchJobTimeline, error) {
+	return c.SearchJobTimelineContext(context.Background(), jobID)
+}
+
+func (c Connection) SearchJobTimelineContext(ctx context.Context, jobID string) (SearchJobTimeline, error) {
+	data := make(url.Values)
+	data.Add("output_mode", "json")
+
+	resp, respCode, err := c.httpCall(ctx, "GET", c.servicePath(fmt.Sprintf("/search/jobs/%s/timeline?%s", jobID, data.Encode())), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return SearchJobTimeline{}, fmt.Errorf("unable to get search job timeline %w %d %s", err, respCode, string(resp))
+	}
+
+	var respStruct SearchJobTimeline
+	if err = json.Unmarshal(resp, &respStruct); err != nil {
+		return SearchJobTimeline{}, fmt.Errorf("unable to parse timeline from splunk: %s | response: %s", err, string(resp))
+	}
+
+	return respStruct, nil
+}
@@ -0,0 +1,147 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// column oriented search results, as returned with output_mode=json_cols.
+// Columns[i] holds the values of Fields[i], values are strings,
+// []interface{} for multivalue fields, or nil
+type Table struct {
+	Fields  []string
+	Columns [][]interface{}
+}
+
+// number of rows in the table
+func (t Table) Len() int {
+	if len(t.Columns) == 0 {
+		return 0
+	}
+
+	return len(t.Columns[0])
+}
+
+// i-th row of the table as a Result, fields with a nil value are left out
+func (t Table) Row(i int) Result {
+	res := make(Result, len(t.Fields))
+	for j, f := range t.Fields {
+		if j < len(t.Columns) && i < len(t.Columns[j]) && t.Columns[j][i] != nil {
+			res[f] = t.Columns[j][i]
+		}
+	}
+
+	return res
+}
+
+// field names as returned by splunk, either plain names or {"name": ...} objects
+type tableFields []string
+
+func (f *tableFields) UnmarshalJSON(b []byte) error {
+	var names []string
+	if err := json.Unmarshal(b, &names); err == nil {
+		*f = names
+		return nil
+	}
+
+	var objects []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(b, &objects); err != nil {
+		return err
+	}
+
+	*f = make([]string, len(objects))
+	for i, o := range objects {
+		(*f)[i] = o.Name
+	}
+
+	return nil
+}
+
+// Get results of a search job with output_mode=json_rows, which doesn't repeat
+// field names on every row. rows[i][j] holds the value of fields[j] for the i-th result
+func (c Connection) SearchJobResultsRows(jobID string, resultsOptions ResultsOptions) ([]string, [][]interface{}, error) {
+	return c.SearchJobResultsRowsContext(context.Background(), jobID, resultsOptions)
+}
+
+func (c Connection) SearchJobResultsRowsContext(ctx context.Context, jobID string, resultsOptions ResultsOptions) ([]string, [][]interface{}, error) {
+	respStruct := struct {
+		Fields tableFields     `json:"fields"`
+		Rows   [][]interface{} `json:"rows"`
+	}{}
+	if err := c.searchJobResultsAs(ctx, jobID, resultsOptions, "json_rows", &respStruct); err != nil {
+		return []string{}, [][]interface{}{}, err
+	}
+
+	return c.transformRows(respStruct.Fields, respStruct.Rows)
+}
+
+// Get results of a search job with output_mode=json_cols, as a Table.
+// with ResultTransforms, the table is rebuilt from the transformed rows
+func (c Connection) SearchJobResultsTable(jobID string, resultsOptions ResultsOptions) (Table, error) {
+	return c.SearchJobResultsTableContext(context.Background(), jobID, resultsOptions)
+}
+
+func (c Connection) SearchJobResultsTableContext(ctx context.Context, jobID string, resultsOptions ResultsOptions) (Table, error) {
+	respStruct := struct {
+		Fields  tableFields     `json:"fields"`
+		Columns [][]interface{} `json:"columns"`
+	}{}
+	if err := c.searchJobResultsAs(ctx, jobID, resultsOptions, "json_cols", &respStruct); err != nil {
+		return Table{}, err
+	}
+
+	table := Table{
+		Fields:  respStruct.Fields,
+		Columns: respStruct.Columns,
+	}
+	if len(c.ResultTransforms) == 0 {
+		return table, nil
+	}
+
+	// transform the table's rows, and turn them back into columns
+	rows := make([][]interface{}, table.Len())
+	for i := range rows {
+		rows[i] = make([]interface{}, len(table.Fields))
+		for j := range table.Fields {
+			if j < len(table.Columns) && i < len(table.Columns[j]) {
+				rows[i][j] = table.Columns[j][i]
+			}
+		}
+	}
+
+	fields, rows, err := c.transformRows(table.Fields, rows)
+	if err != nil {
+		return Table{}, err
+	}
+
+	table = Table{Fields: fields, Columns: make([][]interface{}, len(fields))}
+	for j := range fields {
+		table.Columns[j] = make([]interface{}, len(rows))
+		for i, row := range rows {
+			table.Columns[j][i] = row[j]
+		}
+	}
+
+	return table, nil
+}
+
+// GET results of a search job with outputMode, unmarshalling the response into v
+func (c Connection) searchJobResultsAs(ctx context.Context, jobID string, resultsOptions ResultsOptions, outputMode string, v interface{}) error {
+	data := resultsOptions.values()
+	data.Set("output_mode", outputMode)
+
+	resp, respCode, err := c.httpCall(ctx, "GET", c.servicePath(fmt.Sprintf("/search/jobs/%s/results?%s", jobID, data.Encode())), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return fmt.Errorf("unable to get search job results %w %d %s", err, respCode, string(resp))
+	}
+
+	if err = json.Unmarshal(resp, v); err != nil {
+		return fmt.Errorf("unable to parse %s results from splunk: %s | response: %s", outputMode, err, string(resp))
+	}
+
+	return nil
+}
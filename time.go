@@ -0,0 +1,63 @@
+package go_splunk_rest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// formats splunk returns timestamps in, tried in order by ParseTime
+var splunkTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000-07:00",
+	"2006-01-02 15:04:05.000 MST",
+	"2006-01-02 15:04:05 MST",
+}
+
+// Parse a timestamp returned by splunk: ISO-8601 (as in _time) keeping its utc offset,
+// epoch seconds (as in _indextime) in the local timezone, or TIME_FORMAT in the local timezone
+func ParseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+
+	if epoch, err := strconv.ParseFloat(s, 64); err == nil {
+		sec := int64(epoch)
+		nsec := int64((epoch - float64(sec)) * float64(time.Second))
+		return time.Unix(sec, nsec), nil
+	}
+
+	for _, layout := range splunkTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	if t, err := time.ParseInLocation(TIME_FORMAT, s, time.Local); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("unable to parse time: %s", s)
+}
+
+// _time of the result
+func (r Result) Time() (time.Time, error) {
+	return r.timeField("_time")
+}
+
+// _indextime of the result
+func (r Result) IndexTime() (time.Time, error) {
+	return r.timeField("_indextime")
+}
+
+func (r Result) timeField(field string) (time.Time, error) {
+	v, ok := r[field]
+	if !ok {
+		return time.Time{}, fmt.Errorf("field %s not in result", field)
+	}
+
+	if values, ok := v.([]interface{}); ok && len(values) > 0 {
+		v = values[0]
+	}
+
+	return ParseTime(fmt.Sprintf("%v", v))
+}
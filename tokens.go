@@ -0,0 +1,193 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type TokenOptions struct {
+	Username  string    // name, user the token authenticates as, defaults to the Connection's Username
+	Audience  string    // audience, purpose of the token
+	ExpiresOn time.Time // expires_on, the token doesn't expire when zero
+	NotBefore time.Time // not_before, the token is valid right away when zero
+}
+
+// an authentication token created by splunk
+type AuthToken struct {
+	ID    string `json:"id"`
+	Token string `json:"token"` // JWT, for AuthenticationTokenAuth
+}
+
+// Create an authentication token (JWT), e.g. to bootstrap AuthenticationTokenAuth from basic credentials.
+// token authentication has to be enabled on splunk
+// more details can be found here:
+// https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTaccess#authorization.2Ftokens
+func (c Connection) CreateToken(tokenOptions TokenOptions) (AuthToken, error) {
+	return c.CreateTokenContext(context.Background(), tokenOptions)
+}
+
+func (c Connection) CreateTokenContext(ctx context.Context, tokenOptions TokenOptions) (AuthToken, error) {
+	username := tokenOptions.Username
+	if username == "" {
+		username = c.Username
+	}
+
+	data := make(url.Values)
+	data.Add("name", username)
+	data.Add("audience", tokenOptions.Audience)
+	data.Add("output_mode", "json")
+	if !tokenOptions.ExpiresOn.IsZero() {
+		data.Add("expires_on", fmt.Sprintf("%d", tokenOptions.ExpiresOn.Unix()))
+	}
+	if !tokenOptions.NotBefore.IsZero() {
+		data.Add("not_before", fmt.Sprintf("%d", tokenOptions.NotBefore.Unix()))
+	}
+
+	headers := map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	}
+
+	resp, respCode, err := c.httpCall(ctx, "POST", "/services/authorization/tokens", headers, []byte(data.Encode()))
+	if err != nil || (respCode != http.StatusOK && respCode != http.StatusCreated) {
+		return AuthToken{}, fmt.Errorf("unable to create token %w %d %s", err, respCode, string(resp))
+	}
+
+	respStruct := struct {
+		Entry []struct {
+			Content AuthToken `json:"content"`
+		} `json:"entry"`
+	}{}
+	if err = json.Unmarshal(resp, &respStruct); err != nil {
+		return AuthToken{}, fmt.Errorf("unable to parse token from splunk: %s | response: %s", err, string(resp))
+	}
+	if len(respStruct.Entry) == 0 || respStruct.Entry[0].Content.Token == "" {
+		return AuthToken{}, fmt.Errorf("no token in splunk response: %s", string(resp))
+	}
+
+	return respStruct.Entry[0].Content, nil
+}
+
+// an authentication token as listed by splunk, without the token itself
+type TokenInfo struct {
+	ID        string
+	Username  string
+	Audience  string
+	Status    string // enabled, disabled
+	IssuedAt  time.Time
+	ExpiresOn time.Time // zero if the token doesn't expire
+	NotBefore time.Time
+	LastUsed  time.Time // zero if the token was never used
+}
+
+// List the authentication tokens of username, or of all users when empty
+// (which requires the list_all_tokens capability)
+func (c Connection) ListTokens(username string) ([]TokenInfo, error) {
+	return c.ListTokensContext(context.Background(), username)
+}
+
+func (c Connection) ListTokensContext(ctx context.Context, username string) ([]TokenInfo, error) {
+	data := make(url.Values)
+	data.Add("output_mode", "json")
+	data.Add("count", "0")
+	if username != "" {
+		data.Add("username", username)
+	}
+
+	resp, respCode, err := c.httpCall(ctx, "GET", fmt.Sprintf("/services/authorization/tokens?%s", data.Encode()), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return []TokenInfo{}, fmt.Errorf("unable to list tokens %w %d %s", err, respCode, string(resp))
+	}
+
+	respStruct := struct {
+		Entry []struct {
+			Name    string `json:"name"`
+			Content struct {
+				Claims struct {
+					Sub string `json:"sub"`
+					Aud string `json:"aud"`
+					Iat int64  `json:"iat"`
+					Exp int64  `json:"exp"`
+					Nbf int64  `json:"nbf"`
+				} `json:"claims"`
+				Status   string `json:"status"`
+				LastUsed int64  `json:"lastUsed"`
+			} `json:"content"`
+		} `json:"entry"`
+	}{}
+	if err = json.Unmarshal(resp, &respStruct); err != nil {
+		return []TokenInfo{}, fmt.Errorf("unable to parse tokens from splunk: %s | response: %s", err, string(resp))
+	}
+
+	unix := func(sec int64) time.Time {
+		if sec <= 0 {
+			return time.Time{}
+		}
+		return time.Unix(sec, 0)
+	}
+
+	tokens := make([]TokenInfo, 0, len(respStruct.Entry))
+	for _, e := range respStruct.Entry {
+		tokens = append(tokens, TokenInfo{
+			ID:        e.Name,
+			Username:  e.Content.Claims.Sub,
+			Audience:  e.Content.Claims.Aud,
+			Status:    e.Content.Status,
+			IssuedAt:  unix(e.Content.Claims.Iat),
+			ExpiresOn: unix(e.Content.Claims.Exp),
+			NotBefore: unix(e.Content.Claims.Nbf),
+			LastUsed:  unix(e.Content.LastUsed),
+		})
+	}
+
+	return tokens, nil
+}
+
+// Revoke (delete) tokens of username by ID
+func (c Connection) RevokeTokens(username string, ids ...string) error {
+	return c.RevokeTokensContext(context.Background(), username, ids...)
+}
+
+func (c Connection) RevokeTokensContext(ctx context.Context, username string, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	data := make(url.Values)
+	data.Add("id", strings.Join(ids, ","))
+	data.Add("output_mode", "json")
+
+	resp, respCode, err := c.httpCall(ctx, "DELETE", fmt.Sprintf("/services/authorization/tokens/%s?%s", url.PathEscape(username), data.Encode()), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return fmt.Errorf("unable to revoke tokens %w %d %s", err, respCode, string(resp))
+	}
+
+	return nil
+}
+
+// Revoke all the tokens of username
+func (c Connection) RevokeUserTokens(username string) error {
+	return c.RevokeUserTokensContext(context.Background(), username)
+}
+
+func (c Connection) RevokeUserTokensContext(ctx context.Context, username string) error {
+	if username == "" {
+		return fmt.Errorf("username required to revoke tokens")
+	}
+
+	tokens, err := c.ListTokensContext(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		ids = append(ids, t.ID)
+	}
+
+	return c.RevokeTokensContext(ctx, username, ids...)
+}
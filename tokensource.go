@@ -0,0 +1,145 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "log/slog"
+)
+
+const TOKEN_REFRESH_BEFORE = 5 * time.Minute
+
+// supplies the token for AuthenticationTokenAuth, called for every request
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// TokenSource caching its token, e.g. RefreshingTokenSource. when splunk rejects
+// a token (401, e.g. revoked before it expired) Invalidate is called and the request retried once
+type InvalidatingTokenSource interface {
+	TokenSource
+	Invalidate()
+}
+
+// caches a token, getting a new one from Refresh once it is within RefreshBefore
+// (defaults to TOKEN_REFRESH_BEFORE) of the expiry in its JWT claims.
+// tokens without an expiry (or that aren't JWTs) are kept until Invalidate is called,
+// which happens when splunk rejects the token
+type RefreshingTokenSource struct {
+	Refresh       TokenSource
+	RefreshBefore time.Duration
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// token is used until it is about to expire, it can be empty to get one from refresh right away
+func NewRefreshingTokenSource(token string, refresh TokenSource) *RefreshingTokenSource {
+	s := &RefreshingTokenSource{Refresh: refresh}
+	if token != "" {
+		s.token = token
+		s.expiry = tokenExpiry(token)
+	}
+
+	return s
+}
+
+func (s *RefreshingTokenSource) Token(ctx context.Context) (string, error) {
+	// held while refreshing, so concurrent callers wait on a single refresh
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	refreshBefore := s.RefreshBefore
+	if refreshBefore <= 0 {
+		refreshBefore = TOKEN_REFRESH_BEFORE
+	}
+
+	if s.token != "" && (s.expiry.IsZero() || time.Until(s.expiry) > refreshBefore) {
+		return s.token, nil
+	}
+
+	token, err := s.Refresh.Token(ctx)
+	if err != nil {
+		if s.token != "" && time.Now().Before(s.expiry) {
+			// still valid for a bit, try refreshing again on the next request
+			return s.token, nil
+		}
+		return "", fmt.Errorf("unable to refresh token: %w", err)
+	}
+
+	s.token = token
+	s.expiry = tokenExpiry(token)
+
+	return token, nil
+}
+
+// drop the cached token, so the next call refreshes it
+func (s *RefreshingTokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.token = ""
+}
+
+// TokenSource creating a new token with tokenOptions (see CreateToken) valid for lifetime,
+// on the Connection (e.g. with BasicAuth). wrap it in a RefreshingTokenSource
+func (c Connection) CreateTokenSource(tokenOptions TokenOptions, lifetime time.Duration) TokenSource {
+	return TokenSourceFunc(func(ctx context.Context) (string, error) {
+		opts := tokenOptions
+		opts.ExpiresOn = time.Now().Add(lifetime)
+
+		token, err := c.CreateTokenContext(ctx, opts)
+		if err != nil {
+			return "", err
+		}
+
+		return token.Token, nil
+	})
+}
+
+// expiry of token, zero if it has none or isn't a JWT
+func tokenExpiry(token string) time.Time {
+	expiry, err := jwtExpiry(token)
+	if err != nil {
+		log.Debug("unable to read token expiry, keeping it until rejected", "err", err)
+	}
+
+	return expiry
+}
+
+// expiry (exp claim) of a JWT, zero if it has none. the signature is not verified
+func jwtExpiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("token is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to decode JWT payload: %s", err)
+	}
+
+	claims := struct {
+		Exp int64 `json:"exp"`
+	}{}
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse JWT claims: %s", err)
+	}
+	if claims.Exp <= 0 {
+		return time.Time{}, nil
+	}
+
+	return time.Unix(claims.Exp, 0), nil
+}
@@ -0,0 +1,125 @@
+package go_splunk_rest
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// returned (wrapped) for raw results (e.g. a csv export stream) when the Connection has
+// ResultTransforms, which can't be applied to them
+var ErrRawResults = errors.New("ResultTransforms can't be applied to raw results")
+
+// transforms a result row, returning a nil row drops it from the results
+type ResultTransform func(map[string]interface{}) (map[string]interface{}, error)
+
+// apply the Connection's ResultTransforms to res,
+// returns false if a transform dropped the row
+func (c Connection) transformResult(res map[string]interface{}) (map[string]interface{}, bool, error) {
+	for i, transform := range c.ResultTransforms {
+		var err error
+		res, err = transform(res)
+		if err != nil {
+			return nil, false, fmt.Errorf("result transform %d failed: %w", i, err)
+		}
+		if res == nil {
+			return nil, false, nil
+		}
+	}
+
+	return res, true, nil
+}
+
+// apply the Connection's ResultTransforms to every result, leaving out dropped rows
+func (c Connection) transformResults(results []map[string]interface{}) ([]map[string]interface{}, error) {
+	if len(c.ResultTransforms) == 0 {
+		return results, nil
+	}
+
+	transformed := make([]map[string]interface{}, 0, len(results))
+	for _, res := range results {
+		res, keep, err := c.transformResult(res)
+		if err != nil {
+			return []map[string]interface{}{}, err
+		}
+		if keep {
+			transformed = append(transformed, res)
+		}
+	}
+
+	return transformed, nil
+}
+
+// apply the Connection's ResultTransforms to rows of values of fields (e.g. json_rows or csv results),
+// returning the fields and rows of the kept results, see transformedFields
+func (c Connection) transformRows(fields []string, rows [][]interface{}) ([]string, [][]interface{}, error) {
+	if len(c.ResultTransforms) == 0 {
+		return fields, rows, nil
+	}
+
+	results := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		results[i] = rowResult(fields, row)
+	}
+
+	results, err := c.transformResults(results)
+	if err != nil {
+		return []string{}, [][]interface{}{}, err
+	}
+	if len(results) == 0 {
+		return fields, [][]interface{}{}, nil
+	}
+
+	fields = transformedFields(fields, results)
+	rows = make([][]interface{}, len(results))
+	for i, res := range results {
+		rows[i] = make([]interface{}, len(fields))
+		for j, f := range fields {
+			rows[i][j] = res[f]
+		}
+	}
+
+	return fields, rows, nil
+}
+
+// row of values of fields as a result, with every field set (nil values included)
+// so transforms renaming or removing a field drop its column
+func rowResult(fields []string, row []interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(fields))
+	for j, f := range fields {
+		if j < len(row) {
+			res[f] = row[j]
+		} else {
+			res[f] = nil
+		}
+	}
+
+	return res
+}
+
+// fields of transformed results, those of fields still present first in their order,
+// then those the transforms added, sorted
+func transformedFields(fields []string, results []map[string]interface{}) []string {
+	added := make(map[string]bool)
+	for _, res := range results {
+		for f := range res {
+			added[f] = true
+		}
+	}
+
+	columns := []string{}
+	for _, f := range fields {
+		if added[f] {
+			columns = append(columns, f)
+			delete(added, f)
+		}
+	}
+
+	extra := make([]string, 0, len(added))
+	for f := range added {
+		extra = append(extra, f)
+	}
+	sort.Strings(extra)
+
+	return append(columns, extra...)
+}
@@ -0,0 +1,51 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const DEFAULT_TYPEAHEAD_COUNT = 10
+
+// a search completion suggested by splunk
+type TypeaheadResult struct {
+	Content  string `json:"content"`
+	Count    int    `json:"count"`
+	Operator bool   `json:"operator"`
+}
+
+// Get search completions for prefix (e.g. "index=ma", "sourcetype="),
+// count is the max number of completions, defaults to DEFAULT_TYPEAHEAD_COUNT
+// more details can be found here:
+// https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTsearch#search.2Ftypeahead
+func (c Connection) Typeahead(prefix string, count int) ([]TypeaheadResult, error) {
+	return c.TypeaheadContext(context.Background(), prefix, count)
+}
+
+func (c Connection) TypeaheadContext(ctx context.Context, prefix string, count int) ([]TypeaheadResult, error) {
+	if count <= 0 {
+		count = DEFAULT_TYPEAHEAD_COUNT
+	}
+
+	data := make(url.Values)
+	data.Add("prefix", prefix)
+	data.Add("count", fmt.Sprintf("%d", count))
+	data.Add("output_mode", "json")
+
+	resp, respCode, err := c.httpCall(ctx, "GET", c.servicePath(fmt.Sprintf("/search/typeahead?%s", data.Encode())), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return []TypeaheadResult{}, fmt.Errorf("unable to get typeahead %w %d %s", err, respCode, string(resp))
+	}
+
+	respStruct := struct {
+		Results []TypeaheadResult `json:"results"`
+	}{}
+	if err = json.Unmarshal(resp, &respStruct); err != nil {
+		return []TypeaheadResult{}, fmt.Errorf("unable to parse typeahead from splunk: %s | response: %s", err, string(resp))
+	}
+
+	return respStruct.Results, nil
+}
@@ -0,0 +1,34 @@
+package go_splunk_rest
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Blocking Search, with every result row unmarshalled into a T using its json tags.
+// splunk returns field values as strings (or []string for multivalue fields),
+// so numeric struct fields need the ",string" json tag option
+func SearchTyped[T any](c Connection, searchQuery string, searchOptions SearchOptions) ([]T, error) {
+	results, err := c.Search(searchQuery, searchOptions)
+	if err != nil {
+		return []T{}, err
+	}
+
+	return unmarshalResults[T](results)
+}
+
+func unmarshalResults[T any](results []map[string]interface{}) ([]T, error) {
+	typed := make([]T, len(results))
+	for i, res := range results {
+		b, err := json.Marshal(res)
+		if err != nil {
+			return []T{}, fmt.Errorf("unable to marshal result %d: %s", i, err)
+		}
+
+		if err = json.Unmarshal(b, &typed[i]); err != nil {
+			return []T{}, fmt.Errorf("unable to unmarshal result %d into %T: %s | result: %s", i, typed[i], err, string(b))
+		}
+	}
+
+	return typed, nil
+}
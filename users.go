@@ -0,0 +1,135 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Change the password of username, oldPassword is required when changing
+// the Connection's own password (it can be empty for an admin changing another user's)
+// more details can be found here:
+// https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTaccess#authentication.2Fusers.2F.7Bname.7D
+func (c Connection) UserChangePassword(username, oldPassword, newPassword string) error {
+	return c.UserChangePasswordContext(context.Background(), username, oldPassword, newPassword)
+}
+
+func (c Connection) UserChangePasswordContext(ctx context.Context, username, oldPassword, newPassword string) error {
+	data := make(url.Values)
+	data.Add("password", newPassword)
+	if oldPassword != "" {
+		data.Add("oldpassword", oldPassword)
+	}
+	data.Add("output_mode", "json")
+
+	headers := map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	}
+
+	resp, respCode, err := c.httpCall(ctx, "POST", fmt.Sprintf("/services/authentication/users/%s", url.PathEscape(username)), headers, []byte(data.Encode()))
+	if err != nil || respCode != http.StatusOK {
+		return fmt.Errorf("unable to change password of %s %w %d %s", username, err, respCode, string(resp))
+	}
+
+	return nil
+}
+
+// identity the Connection authenticates as
+type CurrentContext struct {
+	Username     string   `json:"username"`
+	RealName     string   `json:"realname"`
+	Email        string   `json:"email"`
+	Roles        []string `json:"roles"`
+	Capabilities []string `json:"capabilities"`
+	DefaultApp   string   `json:"defaultApp"`
+	TZ           string   `json:"tz"`
+}
+
+// Get the user (with roles and capabilities) the Connection's credentials resolve to
+// more details can be found here:
+// https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTaccess#authentication.2Fcurrent-context
+func (c Connection) CurrentContext() (CurrentContext, error) {
+	return c.CurrentContextContext(context.Background())
+}
+
+func (c Connection) CurrentContextContext(ctx context.Context) (CurrentContext, error) {
+	data := make(url.Values)
+	data.Add("output_mode", "json")
+
+	resp, respCode, err := c.httpCall(ctx, "GET", fmt.Sprintf("/services/authentication/current-context?%s", data.Encode()), map[string]string{}, []byte{})
+	if err != nil || respCode != http.StatusOK {
+		return CurrentContext{}, fmt.Errorf("unable to get current context %w %d %s", err, respCode, string(resp))
+	}
+
+	respStruct := struct {
+		Entry []struct {
+			Content CurrentContext `json:"content"`
+		} `json:"entry"`
+	}{}
+	if err = json.Unmarshal(resp, &respStruct); err != nil {
+		return CurrentContext{}, fmt.Errorf("unable to parse current context from splunk: %s | response: %s", err, string(resp))
+	}
+	if len(respStruct.Entry) == 0 {
+		return CurrentContext{}, fmt.Errorf("no current context in splunk response: %s", string(resp))
+	}
+
+	return respStruct.Entry[0].Content, nil
+}
+
+// returned by RequireCapability when the Connection's user lacks a capability
+type MissingCapabilityError struct {
+	Username   string
+	Capability string
+}
+
+func (e *MissingCapabilityError) Error() string {
+	return fmt.Sprintf("user %s does not have the %s capability", e.Username, e.Capability)
+}
+
+// whether the Connection's user has capability (e.g. "edit_indexes", "admin_all_objects"),
+// directly or through its roles
+func (c Connection) HasCapability(capability string) (bool, error) {
+	return c.HasCapabilityContext(context.Background(), capability)
+}
+
+func (c Connection) HasCapabilityContext(ctx context.Context, capability string) (bool, error) {
+	current, err := c.CurrentContextContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, userCapability := range current.Capabilities {
+		if userCapability == capability {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// fail with a *MissingCapabilityError unless the Connection's user has all of capabilities,
+// to check up front before running an operation needing them
+func (c Connection) RequireCapability(capabilities ...string) error {
+	return c.RequireCapabilityContext(context.Background(), capabilities...)
+}
+
+func (c Connection) RequireCapabilityContext(ctx context.Context, capabilities ...string) error {
+	current, err := c.CurrentContextContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	has := make(map[string]bool, len(current.Capabilities))
+	for _, userCapability := range current.Capabilities {
+		has[userCapability] = true
+	}
+	for _, capability := range capabilities {
+		if !has[capability] {
+			return &MissingCapabilityError{Username: current.Username, Capability: capability}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package go_splunk_rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// credentials read from a HashiCorp Vault KV version 2 secret,
+// GET {Address}/v1/{Mount}/data/{Path}, with the username, password and token keys
+type VaultCredentials struct {
+	Address string // defaults to VAULT_ADDR
+	Token   string // defaults to VAULT_TOKEN
+	Mount   string // defaults to "secret"
+	Path    string
+
+	// keys of the secret's data, defaulting to username, password and token
+	UsernameKey string
+	PasswordKey string
+	TokenKey    string
+
+	Client *http.Client // defaults to a client with a HTTP_TIMEOUT timeout
+}
+
+func (v VaultCredentials) GetCredentials(ctx context.Context) (Credentials, error) {
+	address := v.Address
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	token := v.Token
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	mount := v.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+	client := v.Client
+	if client == nil {
+		client = &http.Client{Timeout: HTTP_TIMEOUT * time.Second}
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimRight(address, "/"), strings.Trim(mount, "/"), strings.TrimLeft(v.Path, "/"))
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("unable to read vault secret %s: %s", v.Path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Credentials{}, fmt.Errorf("unable to read vault secret %s %d %s", v.Path, resp.StatusCode, string(body))
+	}
+
+	respStruct := struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}{}
+	if err = json.Unmarshal(body, &respStruct); err != nil {
+		return Credentials{}, fmt.Errorf("unable to parse vault secret %s: %s", v.Path, err)
+	}
+
+	return secretCredentials(respStruct.Data.Data, v.UsernameKey, v.PasswordKey, v.TokenKey), nil
+}